@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ampCacheSuffix is the host suffix of the Google AMP cache, which serves pages under
+// /c/<host>/<path>, or /c/s/<host>/<path> for pages served over HTTPS.
+const ampCacheSuffix = ".cdn.ampproject.org"
+
+// canonicalAMP returns the URL of the regular page an AMP URL is a version of, and
+// whether u was an AMP URL at all.
+func canonicalAMP(u *url.URL) (*url.URL, bool) {
+	orig := *u
+	amp := false
+
+	// pages served from the AMP cache embed the original URL in their path.
+	if strings.HasSuffix(strings.ToLower(u.Hostname()), ampCacheSuffix) && strings.HasPrefix(u.Path, "/c/") {
+		rest := strings.TrimPrefix(u.Path, "/c/")
+		scheme := "http"
+		if strings.HasPrefix(rest, "s/") {
+			scheme, rest = "https", strings.TrimPrefix(rest, "s/")
+		}
+		if host, path, ok := strings.Cut(rest, "/"); ok && host != "" {
+			orig = url.URL{Scheme: scheme, Host: host, Path: "/" + path, RawQuery: u.RawQuery}
+			amp = true
+		}
+	}
+
+	// publishers put AMP versions under an /amp path segment or behind an amp parameter.
+	switch {
+	case strings.HasSuffix(orig.Path, "/amp") || strings.HasSuffix(orig.Path, "/amp/"):
+		orig.Path = strings.TrimSuffix(strings.TrimSuffix(orig.Path, "/"), "amp")
+		amp = true
+	case strings.HasPrefix(orig.Path, "/amp/"):
+		orig.Path = strings.TrimPrefix(orig.Path, "/amp")
+		amp = true
+	}
+	query := orig.Query()
+	for _, param := range []string{"amp", "outputType"} {
+		if value, ok := query[param]; ok && (param == "amp" || len(value) > 0 && value[0] == "amp") {
+			query.Del(param)
+			amp = true
+		}
+	}
+	if amp {
+		orig.RawQuery = query.Encode()
+		orig.RawPath = ""
+	}
+	return &orig, amp
+}
@@ -0,0 +1,12 @@
+// Package api holds the OpenAPI specification of the HTTP server and the request,
+// response, and routing code generated from it.
+package api
+
+import _ "embed"
+
+//go:generate oapi-codegen -generate types,std-http-server -package api -o api.gen.go openapi.json
+
+// Spec is the OpenAPI specification served at /openapi.json.
+//
+//go:embed openapi.json
+var Spec []byte
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const waybackAvailableURL = "https://archive.org/wayback/available"
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:      "archive",
+		usage:     "record the closest Wayback Machine snapshot of each page",
+		cacheable: true,
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			return func(client *http.Client) (Enricher, error) {
+				return &archiveEnricher{client: client}, nil
+			}
+		},
+	})
+}
+
+// archiveEnricher looks up archived copies of bookmarked pages in the Wayback Machine.
+type archiveEnricher struct {
+	client *http.Client
+}
+
+func (e *archiveEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, waybackAvailableURL+"?url="+url.QueryEscape(b.URL), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("wayback machine returned %s", resp.Status)
+	}
+
+	var result struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding wayback response: %w", err)
+	}
+	if closest := result.ArchivedSnapshots.Closest; closest.Available {
+		b.Archive = closest.URL
+	}
+	return nil
+}
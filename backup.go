@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeLayout stamps backup file names so that they sort by age. The fixed-width
+// fraction keeps runs within the same second from sharing a name.
+const backupTimeLayout = "20060102-150405.000000000"
+
+// outputFlags are the flags naming an output file and controlling the backups taken when
+// it overwrites the input file.
+type outputFlags struct {
+	fs        *flag.FlagSet
+	path      *string
+	backupDir *string
+	backups   *int
+}
+
+// addOutputFlags registers -o with the given usage, along with the backup flags.
+func addOutputFlags(fs *flag.FlagSet, usage string) *outputFlags {
+	return &outputFlags{
+		fs:        fs,
+		path:      fs.String("o", "", usage),
+		backupDir: fs.String("backup-dir", "", "directory for backups of an input file overwritten by -o (defaults to the input's directory)"),
+		backups:   fs.Int("backups", 10, "number of backups of an input file to keep (0 disables backups)"),
+	}
+}
+
+// save writes the output file, or uploads it when -o is a cloud storage URI. Overwriting
+// the input file first backs it up, and the file is only replaced once write succeeds.
+func (o *outputFlags) save(ctx context.Context, write func(w io.Writer) error) error {
+	if isCloudURI(*o.path) {
+		return writeCloudObject(ctx, *o.path, write)
+	}
+	if same, err := sameFile(*o.path, inputPath(o.fs)); err == nil && same && *o.backups > 0 {
+		dir := *o.backupDir
+		if dir == "" {
+			dir = filepath.Dir(*o.path)
+		}
+		if err := backupFile(*o.path, dir, *o.backups); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(*o.path), ".output-*")
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), *o.path); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
+
+// sameFile reports whether two paths name the same existing file.
+func sameFile(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(infoA, infoB), nil
+}
+
+// backupFile copies the file at path into dir as <name>.<timestamp>.bak and deletes all
+// but the newest keep backups of it.
+func backupFile(path, dir string, keep int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading file to back up: %w", err)
+	}
+	name := filepath.Base(path)
+
+	// an existing backup is never overwritten; on a collision the stamp is taken again.
+	var backup string
+	var f *os.File
+	for {
+		backup = filepath.Join(dir, name+"."+time.Now().Format(backupTimeLayout)+".bak")
+		if f, err = os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644); !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "backed up %s to %s\n", path, backup)
+
+	// prune the oldest backups.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error listing backups: %w", err)
+	}
+	var backups []string
+	for _, entry := range entries {
+		if isBackupOf(entry.Name(), name) {
+			backups = append(backups, entry.Name())
+		}
+	}
+	sort.Strings(backups)
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return fmt.Errorf("error removing old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// isBackupOf reports whether file is a backup of the file called name, as written by
+// backupFile: the name, a stamp in backupTimeLayout, and ".bak". Other files in the
+// directory, such as the backups of a file whose name starts the same, are left alone.
+func isBackupOf(file, name string) bool {
+	stamp, ok := strings.CutPrefix(file, name+".")
+	if !ok {
+		return false
+	}
+	stamp, ok = strings.CutSuffix(stamp, ".bak")
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(backupTimeLayout, stamp)
+	return err == nil
+}
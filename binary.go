@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// cborOptions encode timestamps as RFC 3339 strings tagged as date/time (tag 0), which
+// CBOR decoders recognize without knowing the schema.
+var cborOptions = cbor.EncOptions{Time: cbor.TimeRFC3339, TimeTag: cbor.EncTagRequired}
+
+// writeMessagePack writes the bookmark tree as MessagePack, with the keys of the JSON output
+// and timestamps in the MessagePack timestamp extension.
+func writeMessagePack(w io.Writer, tree *Bookmark) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+	return enc.Encode(tree)
+}
+
+// writeCBOR writes the bookmark tree as CBOR, with the keys of the JSON output.
+func writeCBOR(w io.Writer, tree *Bookmark) error {
+	mode, err := cborOptions.EncMode()
+	if err != nil {
+		return fmt.Errorf("error setting up CBOR encoding: %w", err)
+	}
+	return mode.NewEncoder(w).Encode(tree)
+}
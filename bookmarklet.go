@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// bookmarkletScheme starts the URL of a bookmarklet.
+const bookmarkletScheme = "javascript:"
+
+// rawBookmarklets returns the code of every bookmarklet in an HTML export exactly as the
+// file holds it, keyed by the URL the HTML parser reads from it. The parser normalizes
+// line breaks and NUL characters in attribute values as browsers do, which changes the
+// bytes of multi-line bookmarklets.
+func rawBookmarklets(data []byte) map[string]string {
+	exact := make(map[string]string)
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return exact
+		case html.StartTagToken, html.SelfClosingTagToken:
+			// TagName and TagAttr decode the token in place, so the source is copied first.
+			raw := append([]byte(nil), z.Raw()...)
+			name, hasAttr := z.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			var parsed string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				if string(key) == "href" {
+					parsed = string(val)
+					break
+				}
+			}
+			if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(parsed)), bookmarkletScheme) {
+				continue
+			}
+			value, ok := rawAttr(raw, "href")
+			if !ok {
+				continue
+			}
+			// keep only values that differ from the parsed one by normalization alone, as
+			// character references are decoded with rules that differ slightly in
+			// attributes.
+			code := html.UnescapeString(value)
+			if key := normalizeNewlines(code); key == normalizeNewlines(parsed) {
+				exact[key] = code
+			}
+		}
+	}
+}
+
+// normalizeNewlines applies the HTML parser's input normalization to s.
+func normalizeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.ReplaceAll(s, "\x00", "�")
+}
+
+// rawAttr returns the undecoded value of the named attribute in the source of a start
+// tag.
+func rawAttr(tag []byte, name string) (string, bool) {
+	s := string(tag)
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
+
+	// skip "<" and the tag name.
+	i := 1
+	for i < len(s) && !isSpace(s[i]) && s[i] != '>' && s[i] != '/' {
+		i++
+	}
+	for i < len(s) {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) || s[i] == '>' {
+			break
+		}
+		start := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		key := strings.ToLower(s[start:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				end := strings.IndexByte(s[i+1:], quote)
+				if end < 0 {
+					return "", false
+				}
+				value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[start:i]
+			}
+		}
+		if key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,460 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: bookmarks.proto
+
+package bookmarkspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ParseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// html is the exported bookmarks file.
+	Html          []byte `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	mi := &file_bookmarks_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ParseRequest) GetHtml() []byte {
+	if x != nil {
+		return x.Html
+	}
+	return nil
+}
+
+type ParseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tree          *Bookmark              `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	mi := &file_bookmarks_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ParseResponse) GetTree() *Bookmark {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+type ConvertRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Html  []byte                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`
+	// format is an output format name such as "json" or "org".
+	Format        string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_bookmarks_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConvertRequest) GetHtml() []byte {
+	if x != nil {
+		return x.Html
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type ConvertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Output        []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_bookmarks_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConvertResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Html  []byte                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`
+	// query is matched case-insensitively against titles and URLs.
+	Query string `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// limit caps the number of results; zero means no limit.
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_bookmarks_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchRequest) GetHtml() []byte {
+	if x != nil {
+		return x.Html
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_bookmarks_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SearchResult struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Bookmark *Bookmark              `protobuf:"bytes,1,opt,name=bookmark,proto3" json:"bookmark,omitempty"`
+	// folder is the slash separated path of the folder holding the bookmark.
+	Folder        string `protobuf:"bytes,2,opt,name=folder,proto3" json:"folder,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_bookmarks_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_bookmarks_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_bookmarks_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchResult) GetBookmark() *Bookmark {
+	if x != nil {
+		return x.Bookmark
+	}
+	return nil
+}
+
+func (x *SearchResult) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
+}
+
+var File_bookmarks_proto protoreflect.FileDescriptor
+
+const file_bookmarks_proto_rawDesc = "" +
+	"\n" +
+	"\x0fbookmarks.proto\x12\x11parsebookmarks.v1\x1a\x0ebookmark.proto\"\"\n" +
+	"\fParseRequest\x12\x12\n" +
+	"\x04html\x18\x01 \x01(\fR\x04html\"@\n" +
+	"\rParseResponse\x12/\n" +
+	"\x04tree\x18\x01 \x01(\v2\x1b.parsebookmarks.v1.BookmarkR\x04tree\"<\n" +
+	"\x0eConvertRequest\x12\x12\n" +
+	"\x04html\x18\x01 \x01(\fR\x04html\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\")\n" +
+	"\x0fConvertResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\fR\x06output\"O\n" +
+	"\rSearchRequest\x12\x12\n" +
+	"\x04html\x18\x01 \x01(\fR\x04html\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"K\n" +
+	"\x0eSearchResponse\x129\n" +
+	"\aresults\x18\x01 \x03(\v2\x1f.parsebookmarks.v1.SearchResultR\aresults\"_\n" +
+	"\fSearchResult\x127\n" +
+	"\bbookmark\x18\x01 \x01(\v2\x1b.parsebookmarks.v1.BookmarkR\bbookmark\x12\x16\n" +
+	"\x06folder\x18\x02 \x01(\tR\x06folder2\xff\x01\n" +
+	"\x10BookmarksService\x12J\n" +
+	"\x05Parse\x12\x1f.parsebookmarks.v1.ParseRequest\x1a .parsebookmarks.v1.ParseResponse\x12P\n" +
+	"\aConvert\x12!.parsebookmarks.v1.ConvertRequest\x1a\".parsebookmarks.v1.ConvertResponse\x12M\n" +
+	"\x06Search\x12 .parsebookmarks.v1.SearchRequest\x1a!.parsebookmarks.v1.SearchResponseB1Z/github.com/onntztzf/parse-bookmarks/bookmarkspbb\x06proto3"
+
+var (
+	file_bookmarks_proto_rawDescOnce sync.Once
+	file_bookmarks_proto_rawDescData []byte
+)
+
+func file_bookmarks_proto_rawDescGZIP() []byte {
+	file_bookmarks_proto_rawDescOnce.Do(func() {
+		file_bookmarks_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bookmarks_proto_rawDesc), len(file_bookmarks_proto_rawDesc)))
+	})
+	return file_bookmarks_proto_rawDescData
+}
+
+var file_bookmarks_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_bookmarks_proto_goTypes = []any{
+	(*ParseRequest)(nil),    // 0: parsebookmarks.v1.ParseRequest
+	(*ParseResponse)(nil),   // 1: parsebookmarks.v1.ParseResponse
+	(*ConvertRequest)(nil),  // 2: parsebookmarks.v1.ConvertRequest
+	(*ConvertResponse)(nil), // 3: parsebookmarks.v1.ConvertResponse
+	(*SearchRequest)(nil),   // 4: parsebookmarks.v1.SearchRequest
+	(*SearchResponse)(nil),  // 5: parsebookmarks.v1.SearchResponse
+	(*SearchResult)(nil),    // 6: parsebookmarks.v1.SearchResult
+	(*Bookmark)(nil),        // 7: parsebookmarks.v1.Bookmark
+}
+var file_bookmarks_proto_depIdxs = []int32{
+	7, // 0: parsebookmarks.v1.ParseResponse.tree:type_name -> parsebookmarks.v1.Bookmark
+	6, // 1: parsebookmarks.v1.SearchResponse.results:type_name -> parsebookmarks.v1.SearchResult
+	7, // 2: parsebookmarks.v1.SearchResult.bookmark:type_name -> parsebookmarks.v1.Bookmark
+	0, // 3: parsebookmarks.v1.BookmarksService.Parse:input_type -> parsebookmarks.v1.ParseRequest
+	2, // 4: parsebookmarks.v1.BookmarksService.Convert:input_type -> parsebookmarks.v1.ConvertRequest
+	4, // 5: parsebookmarks.v1.BookmarksService.Search:input_type -> parsebookmarks.v1.SearchRequest
+	1, // 6: parsebookmarks.v1.BookmarksService.Parse:output_type -> parsebookmarks.v1.ParseResponse
+	3, // 7: parsebookmarks.v1.BookmarksService.Convert:output_type -> parsebookmarks.v1.ConvertResponse
+	5, // 8: parsebookmarks.v1.BookmarksService.Search:output_type -> parsebookmarks.v1.SearchResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_bookmarks_proto_init() }
+func file_bookmarks_proto_init() {
+	if File_bookmarks_proto != nil {
+		return
+	}
+	file_bookmark_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bookmarks_proto_rawDesc), len(file_bookmarks_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bookmarks_proto_goTypes,
+		DependencyIndexes: file_bookmarks_proto_depIdxs,
+		MessageInfos:      file_bookmarks_proto_msgTypes,
+	}.Build()
+	File_bookmarks_proto = out.File
+	file_bookmarks_proto_goTypes = nil
+	file_bookmarks_proto_depIdxs = nil
+}
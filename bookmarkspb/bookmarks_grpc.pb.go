@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: bookmarks.proto
+
+package bookmarkspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BookmarksService_Parse_FullMethodName   = "/parsebookmarks.v1.BookmarksService/Parse"
+	BookmarksService_Convert_FullMethodName = "/parsebookmarks.v1.BookmarksService/Convert"
+	BookmarksService_Search_FullMethodName  = "/parsebookmarks.v1.BookmarksService/Search"
+)
+
+// BookmarksServiceClient is the client API for BookmarksService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BookmarksService parses exported bookmark files.
+type BookmarksServiceClient interface {
+	// Parse returns the bookmark tree of an exported bookmarks file.
+	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+	// Convert renders an exported bookmarks file in one of the CLI's output formats.
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// Search returns the bookmarks whose title or URL contains the query.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+}
+
+type bookmarksServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBookmarksServiceClient(cc grpc.ClientConnInterface) BookmarksServiceClient {
+	return &bookmarksServiceClient{cc}
+}
+
+func (c *bookmarksServiceClient) Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, BookmarksService_Parse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookmarksServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, BookmarksService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookmarksServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, BookmarksService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BookmarksServiceServer is the server API for BookmarksService service.
+// All implementations must embed UnimplementedBookmarksServiceServer
+// for forward compatibility.
+//
+// BookmarksService parses exported bookmark files.
+type BookmarksServiceServer interface {
+	// Parse returns the bookmark tree of an exported bookmarks file.
+	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
+	// Convert renders an exported bookmarks file in one of the CLI's output formats.
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// Search returns the bookmarks whose title or URL contains the query.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	mustEmbedUnimplementedBookmarksServiceServer()
+}
+
+// UnimplementedBookmarksServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBookmarksServiceServer struct{}
+
+func (UnimplementedBookmarksServiceServer) Parse(context.Context, *ParseRequest) (*ParseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedBookmarksServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedBookmarksServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedBookmarksServiceServer) mustEmbedUnimplementedBookmarksServiceServer() {}
+func (UnimplementedBookmarksServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeBookmarksServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BookmarksServiceServer will
+// result in compilation errors.
+type UnsafeBookmarksServiceServer interface {
+	mustEmbedUnimplementedBookmarksServiceServer()
+}
+
+func RegisterBookmarksServiceServer(s grpc.ServiceRegistrar, srv BookmarksServiceServer) {
+	// If the following call panics, it indicates UnimplementedBookmarksServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BookmarksService_ServiceDesc, srv)
+}
+
+func _BookmarksService_Parse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookmarksServiceServer).Parse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookmarksService_Parse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookmarksServiceServer).Parse(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookmarksService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookmarksServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookmarksService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookmarksServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookmarksService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookmarksServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookmarksService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookmarksServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BookmarksService_ServiceDesc is the grpc.ServiceDesc for BookmarksService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BookmarksService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "parsebookmarks.v1.BookmarksService",
+	HandlerType: (*BookmarksServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Parse",
+			Handler:    _BookmarksService_Parse_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _BookmarksService_Convert_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _BookmarksService_Search_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bookmarks.proto",
+}
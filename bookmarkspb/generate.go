@@ -0,0 +1,5 @@
+// Package bookmarkspb holds the protocol buffer definitions of the bookmark model and the
+// gRPC service, along with the code generated from them.
+package bookmarkspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative bookmark.proto bookmarks.proto
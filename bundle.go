@@ -0,0 +1,124 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// writeBundle writes a zip archive for backups holding the export, an export of each
+// top-level folder under folders/, the site icons under icons/, and a SHA256SUMS file
+// checksumming every other file in the format read by sha256sum -c.
+func writeBundle(filename string, tree *Bookmark, format string, write func(w io.Writer, tree *Bookmark) error) error {
+	// render every file first so a failing writer leaves no partial archive.
+	var names []string
+	files := make(map[string][]byte)
+	add := func(name string, data []byte) {
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+		files[name] = data
+	}
+	render := func(name string, b *Bookmark) error {
+		var buf bytes.Buffer
+		if err := write(&buf, b); err != nil {
+			return fmt.Errorf("error writing %s: %w", name, err)
+		}
+		add(name, buf.Bytes())
+		return nil
+	}
+
+	if err := render("bookmarks."+format, tree); err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for i := range tree.Bookmarks {
+		folder := &tree.Bookmarks[i]
+		if !folder.isFolder() {
+			continue
+		}
+		// folders sharing a name are numbered, skipping names other folders already have.
+		base := bundleName(folder.Title)
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[name] = true
+		if err := render("folders/"+name+"."+format, folder); err != nil {
+			return err
+		}
+	}
+	walkBookmarks(tree, func(b *Bookmark, _ []string) {
+		if name, data, ok := decodeIcon(b.Icon); ok {
+			add("icons/"+name, data)
+		}
+	})
+
+	var sums strings.Builder
+	for _, name := range names {
+		sum := sha256.Sum256(files[name])
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	add("SHA256SUMS", []byte(sums.String()))
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating bundle: %w", err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	now := time.Now()
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return fmt.Errorf("error writing bundle: %w", err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return fmt.Errorf("error writing bundle: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing bundle: %w", err)
+	}
+	return file.Close()
+}
+
+// unsafeNameChars matches runs of characters kept out of archive file names.
+var unsafeNameChars = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]+`)
+
+// bundleName turns a folder title into a file name.
+func bundleName(title string) string {
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(title, "_"), " .")
+	if name == "" {
+		name = "folder"
+	}
+	return name
+}
+
+// decodeIcon decodes an icon data URI, naming it after its hash so icons shared by
+// several bookmarks are stored once.
+func decodeIcon(uri string) (name string, data []byte, ok bool) {
+	header, payload, found := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !found || !strings.HasPrefix(uri, "data:") || !strings.HasSuffix(header, ";base64") {
+		return "", nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", nil, false
+	}
+	ext := ".bin"
+	if exts, _ := mime.ExtensionsByType(strings.TrimSuffix(header, ";base64")); len(exts) > 0 {
+		ext = exts[0]
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]) + ext, data, true
+}
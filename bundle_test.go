@@ -0,0 +1,41 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteBundleIcons(t *testing.T) {
+	f, err := os.Open("bookmarks_test2.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tree, err := Parse(context.Background(), f, WithIcons())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := writeBundle(path, &tree, "json", writeJSON); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	icons := 0
+	for _, file := range zr.File {
+		if strings.HasPrefix(file.Name, "icons/") {
+			icons++
+		}
+	}
+	if icons == 0 {
+		t.Error("bundle holds no icons")
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cachedEnricher wraps a network enricher with an on-disk cache. Each entry is a file
+// named after the hash of the URL in a directory per enricher and set of option values,
+// holding the bookmark fields the enricher changed, so changing an option does not reuse
+// results taken with the old value. Entries older than the TTL, or recorded for a
+// bookmark with a different title, are refreshed.
+type cachedEnricher struct {
+	dir      string // directory of the enricher's entries for its option values.
+	ttl      time.Duration
+	enricher Enricher
+}
+
+// cacheEntry is the on-disk form of a cached enrichment.
+type cacheEntry struct {
+	URL     string                     `json:"url"`
+	Title   string                     `json:"title"`
+	Fetched time.Time                  `json:"fetched"`
+	Fields  map[string]json.RawMessage `json:"fields"`
+}
+
+// defaultCacheDir returns the per-user cache directory, or "" when there is none.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "parse-bookmarks")
+}
+
+// newCachedEnricher caches the results of the named enricher under dir. options describes
+// the values of the enricher's options, such as "follow-redirects=true".
+func newCachedEnricher(name, options, dir string, ttl time.Duration, enricher Enricher) (*cachedEnricher, error) {
+	sum := sha256.Sum256([]byte(options))
+	dir = filepath.Join(dir, name, hex.EncodeToString(sum[:8]))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &cachedEnricher{dir: dir, ttl: ttl, enricher: enricher}, nil
+}
+
+func (c *cachedEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	sum := sha256.Sum256([]byte(b.URL))
+	path := filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+
+	// apply a fresh cached result when there is one.
+	if data, err := os.ReadFile(path); err == nil {
+		var entry cacheEntry
+		if json.Unmarshal(data, &entry) == nil && entry.URL == b.URL && entry.Title == b.Title && time.Since(entry.Fetched) < c.ttl {
+			return overlayFields(b, entry.Fields)
+		}
+	}
+
+	// otherwise enrich the bookmark and remember which fields changed.
+	pageURL, title := b.URL, b.Title
+	before, err := bookmarkFields(b)
+	if err != nil {
+		return err
+	}
+	if err := c.enricher.Enrich(ctx, b); err != nil {
+		return err
+	}
+	changed, err := changedFields(before, b)
+	if err != nil {
+		return err
+	}
+	entry := cacheEntry{URL: pageURL, Title: title, Fetched: time.Now(), Fields: changed}
+
+	// write through a temporary file so concurrent readers never see a partial entry.
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// bookmarkFields returns the bookmark's JSON fields keyed by name.
+func bookmarkFields(b *Bookmark) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// changedFields returns the bookmark's JSON fields that differ from before.
+func changedFields(before map[string]json.RawMessage, b *Bookmark) (map[string]json.RawMessage, error) {
+	after, err := bookmarkFields(b)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]json.RawMessage)
+	for key, value := range after {
+		if string(before[key]) != string(value) {
+			changed[key] = value
+		}
+	}
+	return changed, nil
+}
+
+// overlayFields sets the given JSON fields on the bookmark, leaving the others alone.
+func overlayFields(b *Bookmark, overlay map[string]json.RawMessage) error {
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, b)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:      "canonical-urls",
+		usage:     "rewrite AMP links to the regular page and expand shortened links, keeping the original URL",
+		cacheable: true,
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			shorteners := fs.String("shorteners", "bit.ly,t.co", "comma-separated domains of link shorteners expanded by -canonical-urls")
+			return func(client *http.Client) (Enricher, error) {
+				return newCanonicalizer(*shorteners, client), nil
+			}
+		},
+	})
+}
+
+// maxShortenerHops bounds how many redirects are followed when expanding a link, as
+// shortened links sometimes point at other shorteners.
+const maxShortenerHops = 5
+
+// canonicalizer rewrites links to their durable form. AMP URLs are resolved offline,
+// while links on shortener domains are expanded by requesting them without following
+// the redirect they answer with.
+type canonicalizer struct {
+	shorteners map[string]bool
+	client     *http.Client
+}
+
+func newCanonicalizer(shorteners string, client *http.Client) *canonicalizer {
+	c := &canonicalizer{shorteners: make(map[string]bool)}
+	for _, domain := range strings.Split(shorteners, ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			c.shorteners[domain] = true
+		}
+	}
+	// redirects are read one at a time so only those of shorteners are followed.
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	c.client = &noRedirects
+	return c
+}
+
+// Enrich replaces the bookmark's URL with its canonical form, recording the URL it had
+// before unless an earlier run already did.
+func (c *canonicalizer) Enrich(ctx context.Context, b *Bookmark) error {
+	u, err := url.Parse(b.URL)
+	if err != nil {
+		return err
+	}
+	for hops := 0; c.shorteners[strings.ToLower(u.Hostname())]; hops++ {
+		if hops == maxShortenerHops {
+			return fmt.Errorf("too many redirects expanding %s", b.URL)
+		}
+		if u, err = c.expand(ctx, u); err != nil {
+			return err
+		}
+	}
+	u, _ = canonicalAMP(u)
+	if canonical := u.String(); canonical != b.URL {
+		if b.OriginalURL == "" {
+			b.OriginalURL = b.URL
+		}
+		b.URL = canonical
+		currentReport.count("rewritten", 1)
+	}
+	return nil
+}
+
+// expand returns the URL a shortened link redirects to.
+func (c *canonicalizer) expand(ctx context.Context, u *url.URL) (*url.URL, error) {
+	// some shorteners do not answer HEAD requests, so GET is used and the body ignored.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	location, err := resp.Location()
+	if errors.Is(err, http.ErrNoLocation) {
+		return nil, fmt.Errorf("http %d without a redirect expanding %s", resp.StatusCode, u)
+	}
+	return location, err
+}
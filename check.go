@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// checker inspects a bookmark and describes what is wrong with it, or returns "" when
+// nothing is.
+type checker func(ctx context.Context, b *Bookmark) (string, error)
+
+// runCheck implements the check subcommand, reporting bookmarks that fail any enabled check.
+func runCheck(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	links := fs.Bool("links", false, "fetch each URL, recording its status, content type, and final URL")
+	followRedirects := fs.Bool("follow-redirects", false, "follow redirect chains when fetching URLs (implies -links)")
+	rewrite := fs.Bool("rewrite", false, "replace redirected URLs with their final destination (implies -follow-redirects)")
+	timeout := fs.Duration("timeout", 15*time.Second, "timeout for each link request")
+	output := addOutputFlags(fs, "write the checked tree as JSON to this file")
+	metricsAddr := fs.String("metrics-addr", "", "address to expose Prometheus metrics on while checking")
+	statePath := fs.String("state", "", "state file used to only check bookmarks that are new or changed since the last run")
+	safeBrowsing := fs.Bool("safe-browsing", false, "look up URLs in the Google Safe Browsing API")
+	safeBrowsingKey := fs.String("safe-browsing-key", os.Getenv("SAFE_BROWSING_KEY"), "Safe Browsing API key (defaults to $SAFE_BROWSING_KEY)")
+	blocklistPath := fs.String("blocklist", "", "file of blocked hosts or URL prefixes, one per line")
+	dryRun := fs.Bool("dry-run", false, "print the changes that -o would save as a diff instead of writing the output and state files")
+	return func(ctx context.Context) error {
+		if *metricsAddr != "" {
+			serveMetrics(*metricsAddr)
+		}
+
+		// collect the enabled checks, naming each with its options for the state file.
+		var checkers []checker
+		var names []string
+		if *links || *followRedirects || *rewrite {
+			checkers = append(checkers, newLinkChecker(*timeout, *followRedirects || *rewrite).check)
+			names = append(names, fmt.Sprintf("links(follow-redirects=%t)", *followRedirects || *rewrite))
+		}
+		if *safeBrowsing {
+			if *safeBrowsingKey == "" {
+				return fmt.Errorf("-safe-browsing requires an API key")
+			}
+			checkers = append(checkers, newSafeBrowsing(*safeBrowsingKey).check)
+			names = append(names, "safe-browsing")
+		}
+		if *blocklistPath != "" {
+			blocklist, err := loadBlocklist(*blocklistPath)
+			if err != nil {
+				return err
+			}
+			checkers = append(checkers, blocklist.check)
+			names = append(names, "blocklist("+blocklist.digest()+")")
+		}
+		if len(checkers) == 0 {
+			return fmt.Errorf("no checks enabled")
+		}
+
+		var state *runState
+		if *statePath != "" {
+			var err error
+			if state, err = loadRunState(*statePath, "check "+strings.Join(names, " ")); err != nil {
+				return err
+			}
+		}
+
+		tree, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		var before []string
+		if *dryRun {
+			before = treeLines(&tree)
+		}
+
+		// run every check against every link and report the problems found. Bookmarks
+		// unchanged since the last run report the problems recorded then.
+		flagged := 0
+		walkBookmarks(&tree, func(b *Bookmark, path []string) {
+			if err != nil || b.isFolder() {
+				return
+			}
+			hash := bookmarkHash(b)
+			entry, ok := state.lookup(hash)
+			if ok {
+				overlayFields(b, entry.Fields)
+			} else {
+				before, _ := bookmarkFields(b)
+				for _, check := range checkers {
+					var problem string
+					if problem, err = check(ctx, b); err != nil {
+						err = fmt.Errorf("error checking %q: %w", b.URL, err)
+						return
+					}
+					if problem != "" {
+						entry.Problems = append(entry.Problems, problem)
+					}
+				}
+				entry.Fields, _ = changedFields(before, b)
+			}
+			state.record(hash, entry)
+
+			for _, problem := range entry.Problems {
+				fmt.Printf("%s\t%s\t%s\t%s\n", folderPath(path), b.Title, b.URL, problem)
+			}
+			if len(entry.Problems) > 0 {
+				flagged++
+			}
+		})
+		// an interrupted run still saves what was checked so far.
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if *dryRun {
+			state = nil
+		}
+		if err := state.save(); err != nil {
+			return err
+		}
+		if *rewrite {
+			fmt.Printf("rewrote %d redirected URLs\n", rewriteRedirects(&tree))
+		}
+
+		// save the tree along with whatever the checks recorded on it.
+		if *dryRun {
+			writeTreeDiff(os.Stdout, inputPath(fs), before, &tree)
+		} else if *output.path != "" {
+			if err := output.save(ctx, func(w io.Writer) error { return writeJSON(w, &tree) }); err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if flagged > 0 {
+			return fmt.Errorf("%d bookmarks flagged", flagged)
+		}
+		return nil
+	}
+}
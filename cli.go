@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// commands maps subcommand names to functions registering the subcommand's flags, which
+// return the function running it once the flags are parsed.
+var commands = map[string]func(fs *flag.FlagSet) func(ctx context.Context) error{
+	"check":    runCheck,
+	"convert":  convert,
+	"dupes":    runDupes,
+	"enrich":   runEnrich,
+	"grpc":     runGRPC,
+	"keywords": runKeywords,
+	"merge":    runMerge,
+	"notion":   runNotion,
+	"serve":    runServe,
+	"stream":   runStream,
+	"sync":     runSync,
+}
+
+// formats maps output format names to writers serializing the bookmark tree.
+var formats = map[string]func(w io.Writer, tree *Bookmark) error{
+	"cbor":    writeCBOR,
+	"esbulk":  writeESBulk,
+	"html":    writeHTML,
+	"json":    writeJSON,
+	"msgpack": writeMessagePack,
+	"ndjson":  writeNDJSON,
+	"org":     writeOrg,
+	"parquet": writeParquet,
+	"pb":      writeProtobuf,
+	"pbjson":  writeProtobufJSON,
+	"toml":    writeTOML,
+}
+
+// flatFormats maps the names of output formats writing a record per link to writers
+// taking those records, which can be paged through.
+var flatFormats = map[string]func(w io.Writer, rows []flatBookmark) error{
+	"esbulk":  writeESBulkRows,
+	"ndjson":  writeNDJSONRows,
+	"parquet": writeParquetRows,
+}
+
+// convert parses the input file and prints the bookmark tree in the requested format.
+func convert(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	format := fs.String("format", "json", "output format (cbor, esbulk, html, json, msgpack, ndjson, org, parquet, pb, pbjson, toml, or an output plugin name)")
+	unsafeURLs := fs.String("unsafe-urls", "flag", "how to treat javascript:, data: and vbscript: URLs (keep, flag, strip)")
+	var allowScripts stringList
+	fs.Var(&allowScripts, "allow-script", "title or URL prefix of an intentional bookmarklet to leave alone (repeatable)")
+	allowDomains := fs.String("allow-domains", "", "file of host patterns, one per line with * wildcards, outside which links are dropped")
+	denyDomains := fs.String("deny-domains", "", "file of host patterns, one per line with * wildcards, whose links are dropped")
+	rulesPath := fs.String("rules", "", "JSON rules file filing links into folders and tagging them by domain, URL pattern, or title keywords")
+	archiveAge := fs.String("archive-older-than", "", "move links neither added, modified, nor visited within this age (such as 3y or 90d) into Archive/<year> (implies -history)")
+	archiveDrop := fs.Bool("archive-drop", false, "remove the links -archive-older-than selects instead of moving them")
+	sortBy := fs.String("sort", "", "sort the entries of every folder by title, added, visits, or frecency (the last two imply -history)")
+	locale := fs.String("locale", "", "BCP 47 locale whose collation rules order titles with -sort title")
+	normalizeRoots := fs.Bool("normalize-roots", false, "rename browser special folders (bookmarks bar, other bookmarks, ...) to canonical titles")
+	mojibake := fs.Bool("fix-mojibake", false, "repair titles and descriptions whose UTF-8 was decoded with the wrong charset, such as \"Ã¤\" for \"ä\"")
+	execCommand := fs.String("exec-per-bookmark", "", "shell command receiving each bookmark as JSON, which may drop (exit 1) or replace it (JSON on stdout)")
+	output := addOutputFlags(fs, "write the export to this file instead of stdout")
+	bundlePath := fs.String("bundle", "", "write a zip archive of the export, per-folder exports, icons, and SHA-256 checksums instead of printing the export (implies -icons)")
+	folder := fs.String("folder", "", "write only the folders whose path below the root matches this pattern, such as Dev or Dev/*, gathering several under the root")
+	exportsPath := fs.String("exports", "", "JSON file mapping folder patterns to output files and formats, all written instead of the export")
+	limit := fs.Int("limit", 0, "write at most this many links, with a flat format (esbulk, ndjson, parquet)")
+	offset := fs.Int("offset", 0, "skip this many links first, with a flat format (esbulk, ndjson, parquet)")
+	newest := fs.Int("newest", 0, "write only this many of the most recently added links, newest first, with a flat format (esbulk, ndjson, parquet)")
+	disambiguate := fs.String("disambiguate", "", "rename entries sharing a title with a sibling, numbering all but the first (suffix) or all of them (index), and report the collisions")
+	folderStats := fs.Bool("folder-stats", false, "add count, deepCount, and newestAddAt fields to every folder")
+	dryRun := fs.Bool("dry-run", false, "print the changes made to the tree as a diff instead of writing anything")
+	manifestPath := fs.String("manifest", "", "manifest file recording a hash of every link; changes since the previous run are reported on stderr")
+	return func(ctx context.Context) error {
+		// formats not built in may be provided by an output plugin.
+		write, ok := formats[*format]
+		if !ok {
+			if write, ok = outputPlugin(*format); !ok {
+				return fmt.Errorf("unknown format %q", *format)
+			}
+		}
+		// archiving goes by the last visit too, and sorting by visits needs them, so the visit
+		// data must not be dropped.
+		if *archiveAge != "" || *sortBy == "visits" || *sortBy == "frecency" {
+			fs.Set("history", "true")
+		}
+		// bundles hold the site icons, so they must not be dropped either.
+		if *bundlePath != "" {
+			fs.Set("icons", "true")
+		}
+		if *limit != 0 || *offset != 0 || *newest != 0 {
+			writeRows, ok := flatFormats[*format]
+			switch {
+			case !ok:
+				return fmt.Errorf("-limit, -offset, and -newest need a flat format, not %q", *format)
+			case *limit < 0 || *offset < 0 || *newest < 0:
+				return fmt.Errorf("-limit, -offset, and -newest cannot be negative")
+			case *limit > 0 && *newest > 0:
+				return fmt.Errorf("-limit cannot be combined with -newest")
+			}
+			write = func(w io.Writer, tree *Bookmark) error {
+				return writeRows(w, pageRows(flattenBookmarks(tree), *offset, max(*limit, *newest), *newest > 0))
+			}
+		}
+		var exports []export
+		if *exportsPath != "" {
+			var err error
+			if exports, err = loadExports(*exportsPath); err != nil {
+				return err
+			}
+		}
+
+		tree, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		if *folder != "" {
+			selected := selectFolders(&tree, *folder)
+			if selected == nil {
+				return fmt.Errorf("no folder matches %q", *folder)
+			}
+			tree = *selected
+		}
+		var before []string
+		if *dryRun {
+			before = treeLines(&tree)
+		}
+		normalizeSpecialFolders(&tree, *normalizeRoots)
+		if *mojibake {
+			walkBookmarks(&tree, func(b *Bookmark, path []string) {
+				title, description := b.Title, b.Description
+				fixMojibake(b)
+				if b.Title != title || b.Description != description {
+					currentReport.count("rewritten", 1)
+				}
+			})
+		}
+		if err := sanitizeURLs(&tree, *unsafeURLs, allowScripts); err != nil {
+			return err
+		}
+		if *allowDomains != "" || *denyDomains != "" {
+			var allow, deny []string
+			if *allowDomains != "" {
+				if allow, err = loadDomainList(*allowDomains); err != nil {
+					return err
+				}
+			}
+			if *denyDomains != "" {
+				if deny, err = loadDomainList(*denyDomains); err != nil {
+					return err
+				}
+			}
+			removed := filterDomains(&tree, allow, deny)
+			currentReport.count("dropped", removed)
+			fmt.Fprintf(os.Stderr, "domain lists removed %d links\n", removed)
+		}
+		if *rulesPath != "" {
+			rules, err := loadRules(*rulesPath)
+			if err != nil {
+				return err
+			}
+			moved := applyRules(&tree, rules)
+			currentReport.count("moved", moved)
+			fmt.Fprintf(os.Stderr, "rules moved %d links\n", moved)
+		}
+		if *archiveAge != "" {
+			cutoff, err := parseAge(*archiveAge, time.Now())
+			if err != nil {
+				return err
+			}
+			stale := archiveStale(&tree, cutoff, *archiveDrop, os.Stderr)
+			if *archiveDrop {
+				currentReport.count("dropped", stale)
+			} else {
+				currentReport.count("archived", stale)
+			}
+			fmt.Fprintf(os.Stderr, "%d links inactive since %s\n", stale, cutoff.Format("2006-01-02"))
+		}
+		if *sortBy != "" {
+			if err := sortBookmarks(&tree, *sortBy, *locale); err != nil {
+				return err
+			}
+		}
+		if *execCommand != "" {
+			if err := execPerBookmark(ctx, &tree, *execCommand); err != nil {
+				return err
+			}
+		}
+		if *disambiguate != "" {
+			renamed, err := disambiguateTitles(&tree, *disambiguate, os.Stderr)
+			if err != nil {
+				return err
+			}
+			currentReport.count("renamed", renamed)
+		}
+		// statistics come last so they describe the tree as written.
+		if *folderStats {
+			addFolderStats(&tree)
+		}
+		if *dryRun {
+			writeTreeDiff(os.Stdout, inputPath(fs), before, &tree)
+			return nil
+		}
+		switch {
+		case *bundlePath != "":
+			err = writeBundle(*bundlePath, &tree, *format, write)
+		case exports != nil:
+			err = writeExports(ctx, &tree, exports, output)
+		case *output.path != "":
+			err = output.save(ctx, func(w io.Writer) error { return write(w, &tree) })
+		default:
+			err = write(os.Stdout, &tree)
+		}
+		if err != nil {
+			return err
+		}
+		// exports record each file they write themselves.
+		if exports == nil {
+			path := *output.path
+			if *bundlePath != "" {
+				path = *bundlePath
+			}
+			currentReport.output(path, &tree)
+		}
+
+		// report what changed since the manifest was last written, then update it.
+		if *manifestPath != "" {
+			previous, err := loadManifest(*manifestPath)
+			if err != nil {
+				return err
+			}
+			current := buildManifest(&tree)
+			if previous != nil {
+				reportChanges(os.Stderr, previous, current)
+			}
+			return current.save(*manifestPath)
+		}
+		return nil
+	}
+}
+
+// runCommand parses the arguments of the named subcommand and runs it. Every subcommand
+// accepts -report, which starts collecting the run report.
+func runCommand(ctx context.Context, name string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	run := commands[name](fs)
+	report := addReportFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *report != "" {
+		startReport(name, args, *report)
+	}
+	return run(ctx)
+}
+
+// addReportFlag registers the -report flag every subcommand accepts.
+func addReportFlag(fs *flag.FlagSet) *string {
+	return fs.String("report", "", "write a JSON summary of the run, with its inputs, outputs, counts, and warnings, to this file")
+}
+
+// commandFlags returns the flags the named subcommand accepts, or nil when there is no
+// such subcommand. Parsing them reports errors instead of exiting.
+func commandFlags(name string) *flag.FlagSet {
+	flags, ok := commands[name]
+	if !ok {
+		return nil
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags(fs)
+	addReportFlag(fs)
+	return fs
+}
+
+// stringList is a flag value collecting every occurrence of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// writeJSON writes the bookmark tree as a single line of JSON.
+func writeJSON(w io.Writer, tree *Bookmark) error {
+	// convert the bookmark tree to JSON and print the result.
+	jsonData, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("error converting to JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(jsonData))
+	return err
+}
+
+// addInputFlags registers the flags controlling how the input file is read and returns a
+// function loading it once the flags are parsed. The input file is the first argument,
+// falling back to the sample export.
+func addInputFlags(fs *flag.FlagSet) func(ctx context.Context) (Bookmark, error) {
+	load := addInputFileFlags(fs)
+	return func(ctx context.Context) (Bookmark, error) {
+		return load(ctx, inputPath(fs))
+	}
+}
+
+// addInputFileFlags registers the flags of addInputFlags for subcommands reading several
+// input files, returning a function that loads any of them.
+func addInputFileFlags(fs *flag.FlagSet) func(ctx context.Context, path string) (Bookmark, error) {
+	rootTitle := fs.String("root-title", "", "title of the root folder synthesized for exports without one (defaults to the H1 title of HTML exports, or "+defaultRootTitle+")")
+	inputFormat := fs.String("input-format", "", "format of the input file ("+strings.Join(importerNames(), ", ")+"); detected from its contents by default")
+	var headers stringList
+	fs.Var(&headers, "input-header", "\"Name: value\" header sent when the input is an http(s) URL (repeatable)")
+	bookmarklets := fs.Bool("bookmarklets", false, "keep the code of javascript: bookmarklets in HTML exports byte for byte (see -allow-script to leave them unflagged)")
+	icons := fs.Bool("icons", false, "keep the icons HTML exports embed as data URIs, which are dropped by default")
+	history := fs.Bool("history", false, "keep the visit counts, last visits, and frecency of links read from a Firefox places.sqlite")
+	token := fs.String("input-token", os.Getenv("BOOKMARKS_INPUT_TOKEN"), "bearer token sent when the input is an http(s) URL (defaults to $BOOKMARKS_INPUT_TOKEN)")
+	return func(ctx context.Context, path string) (Bookmark, error) {
+		header, err := parseHeaders(headers)
+		if err != nil {
+			return Bookmark{}, err
+		}
+		if *token != "" && header.Get("Authorization") == "" {
+			header.Set("Authorization", "Bearer "+*token)
+		}
+		var opts []Option
+		if *bookmarklets {
+			opts = append(opts, WithBookmarklets())
+		}
+		if *icons {
+			opts = append(opts, WithIcons())
+		}
+		tree, err := loadBookmarks(ctx, path, header, *rootTitle, *inputFormat, opts...)
+		if err == nil && !*history {
+			walkBookmarks(&tree, func(b *Bookmark, path []string) {
+				b.Visits, b.LastVisit, b.Frecency = 0, nil, 0
+			})
+		}
+		return tree, err
+	}
+}
+
+// inputPath returns the input file named by the parsed arguments.
+func inputPath(fs *flag.FlagSet) string {
+	if fs.NArg() > 0 {
+		return fs.Arg(0)
+	}
+	return "bookmarks_test1.html"
+}
+
+// loadBookmarks reads an exported bookmarks file, or downloads it with the given headers
+// when path is an http(s) URL or from cloud storage when it is an s3:// or gs:// URI, and
+// returns its bookmark tree. An empty format detects the file's format from its contents.
+// Gzip-compressed files and zip archives holding the export are decompressed first. HTML
+// exports are parsed with opts.
+func loadBookmarks(ctx context.Context, path string, header http.Header, rootTitle, format string, opts ...Option) (tree Bookmark, err error) {
+	defer func() { currentReport.input(path, &tree, err) }()
+
+	// read the file containing the bookmarks data.
+	var data []byte
+	if isRemoteInput(path) {
+		data, err = fetchInput(ctx, path, header)
+	} else if isCloudURI(path) {
+		data, err = readCloudObject(ctx, path)
+	} else if data, err = ioutil.ReadFile(path); err != nil {
+		err = fmt.Errorf("error reading file: %w", err)
+	}
+	if err != nil {
+		return Bookmark{}, err
+	}
+	if data, err = decompressInput(data); err != nil {
+		return Bookmark{}, err
+	}
+	parse, err := findImporter(format, data, opts...)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	tree, err = parse(ctx, bytes.NewReader(data), rootTitle)
+	observeParse(&tree, err)
+	return tree, err
+}
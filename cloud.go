@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+// isCloudURI reports whether path names an object in S3 (s3://bucket/key) or Google Cloud
+// Storage (gs://bucket/key). Credentials come from the environment as the providers' own
+// tools read them; S3 URIs may set the region with ?region=.
+func isCloudURI(path string) bool {
+	return strings.HasPrefix(path, "s3://") || strings.HasPrefix(path, "gs://")
+}
+
+// openCloudObject opens the bucket of a cloud storage URI and returns it along with the
+// object's key.
+func openCloudObject(ctx context.Context, uri string) (*blob.Bucket, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, "", fmt.Errorf("missing bucket or object name")
+	}
+	// the query configures the bucket, as in s3://bucket?region=eu-west-1.
+	u.Path = ""
+	bucket, err := blob.OpenBucket(ctx, u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	return bucket, key, nil
+}
+
+// readCloudObject downloads an object from cloud storage.
+func readCloudObject(ctx context.Context, uri string) ([]byte, error) {
+	bucket, key, err := openCloudObject(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", uri, err)
+	}
+	defer bucket.Close()
+	data, err := bucket.ReadAll(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", uri, err)
+	}
+	return data, nil
+}
+
+// writeCloudObject uploads what write produces to cloud storage. The object is only
+// created or replaced once write succeeds.
+func writeCloudObject(ctx context.Context, uri string, write func(w io.Writer) error) error {
+	bucket, key, err := openCloudObject(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", uri, err)
+	}
+	defer bucket.Close()
+
+	// canceling the writer's context before closing it aborts the upload.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", uri, err)
+	}
+	if err := write(w); err != nil {
+		cancel()
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", uri, err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// the completion subcommand reaches the other subcommands through commands, so it is
+// added here rather than in its initializer.
+func init() {
+	commands["completion"] = runCompletion
+}
+
+// completeCommand is the hidden subcommand the completion scripts call to list the
+// candidates for the word being completed. It takes the words as they are, without
+// parsing flags, so main dispatches to it before the other subcommands.
+const completeCommand = "__complete"
+
+// flagValues lists the values of flags taking one from a known set, given the flags
+// parsed from the words typed before the one being completed.
+var flagValues = map[string]func(fs *flag.FlagSet) []string{
+	"format":       func(*flag.FlagSet) []string { return formatNames() },
+	"input-format": func(*flag.FlagSet) []string { return importerNames() },
+	"unsafe-urls":  fixedValues("keep", "flag", "strip"),
+	"sort":         fixedValues("title", "added", "visits", "frecency"),
+	"merge":        fixedValues("first", "oldest", "newest"),
+	"prefer":       fixedValues("first", "newest", "oldest", "interactive"),
+	"every":        fixedValues("1h", "6h", "24h"),
+	"disambiguate": fixedValues("suffix", "index"),
+	"folder":       folderPaths,
+}
+
+// fixedValues returns a flagValues entry listing the same values whatever was typed.
+func fixedValues(values ...string) func(*flag.FlagSet) []string {
+	return func(*flag.FlagSet) []string { return values }
+}
+
+// folderPaths lists the paths below the root of the folders of the input named by the
+// typed words, or of the default input, as -folder matches them. Inputs that would have
+// to be downloaded are left alone, as completion has to be quick.
+func folderPaths(fs *flag.FlagSet) []string {
+	path := inputPath(fs)
+	if strings.Contains(path, "://") {
+		return nil
+	}
+	format := ""
+	if f := fs.Lookup("input-format"); f != nil {
+		format = f.Value.String()
+	}
+	tree, err := loadBookmarks(context.Background(), path, nil, "", format)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	walkBookmarks(&tree, func(b *Bookmark, titles []string) {
+		if b.isFolder() {
+			paths = append(paths, folderPath(append(titles[1:len(titles):len(titles)], b.Title)))
+		}
+	})
+	return paths
+}
+
+// completionScripts hold the completion script of each shell. Every %[1]s is replaced by
+// the program name and %[2]s by a shell identifier derived from it.
+var completionScripts = map[string]string{
+	"bash": `_%[2]s() {
+    local cur=${COMP_WORDS[COMP_CWORD]} IFS=$'\n'
+    COMPREPLY=($(compgen -W "$(%[1]s __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)" -- "$cur"))
+    if [ ${#COMPREPLY[@]} -eq 0 ]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+    fi
+}
+complete -o filenames -F _%[2]s %[1]s
+`,
+	"zsh": `#compdef %[1]s
+
+_%[2]s() {
+    local -a candidates
+    candidates=("${(@f)$(%[1]s __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    compadd -- "${candidates[@]}" || _files
+}
+
+compdef _%[2]s %[1]s
+`,
+	"fish": `function __%[2]s_complete
+    set -l current (commandline -ct)
+    set -l candidates (string match -- "$current*" (%[1]s __complete (commandline -opc)[2..-1] "$current" 2>/dev/null))
+    if test (count $candidates) -gt 0
+        printf '%%s\n' $candidates
+    else
+        __fish_complete_path (commandline -ct)
+    end
+end
+
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`,
+}
+
+// runCompletion implements the completion subcommand, printing the completion script
+// for a shell.
+func runCompletion(fs *flag.FlagSet) func(ctx context.Context) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s completion bash|zsh|fish\n", programName())
+	}
+	return func(ctx context.Context) error {
+		script, ok := completionScripts[fs.Arg(0)]
+		if !ok {
+			fs.Usage()
+			return fmt.Errorf("unsupported shell %q", fs.Arg(0))
+		}
+		name := programName()
+		fmt.Printf(script, name, strings.Map(func(r rune) rune {
+			if r == '-' || r == '.' {
+				return '_'
+			}
+			return r
+		}, name))
+		return nil
+	}
+}
+
+// runComplete prints the candidates for the last of the words typed after the program
+// name, one per line. Nothing is printed when the shell should complete file names.
+func runComplete(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	current, typed := args[len(args)-1], args[:len(args)-1]
+
+	// the first word names a subcommand; without one the arguments are convert's.
+	command := "convert"
+	if len(typed) > 0 {
+		if _, ok := commands[typed[0]]; ok {
+			command, typed = typed[0], typed[1:]
+		}
+	} else if !strings.HasPrefix(current, "-") {
+		for _, name := range commandNames() {
+			fmt.Println(name)
+		}
+	}
+	if command == "completion" {
+		for shell := range completionScripts {
+			fmt.Println(shell)
+		}
+		return nil
+	}
+	fs := commandFlags(command)
+	if fs == nil {
+		return nil
+	}
+	// the words typed so far name the input whose folders -folder completes; a flag
+	// still missing its value stops parsing, which is as far as it needs to go.
+	fs.Parse(typed)
+
+	// complete the value of a flag given as -flag=value or as the word after -flag.
+	if name, value, ok := strings.Cut(strings.TrimLeft(current, "-"), "="); ok && strings.HasPrefix(current, "-") {
+		printValues(fs, name, strings.TrimSuffix(current, value))
+		return nil
+	}
+	if len(typed) > 0 && strings.HasPrefix(typed[len(typed)-1], "-") {
+		name := strings.TrimLeft(typed[len(typed)-1], "-")
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && !strings.Contains(name, "=") {
+			printValues(fs, name, "")
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		fs.VisitAll(func(f *flag.Flag) {
+			fmt.Println("-" + f.Name)
+		})
+	}
+	return nil
+}
+
+// printValues prints the known values of a flag, each after prefix.
+func printValues(fs *flag.FlagSet, name, prefix string) {
+	if values, ok := flagValues[name]; ok {
+		for _, value := range values(fs) {
+			fmt.Println(prefix + value)
+		}
+	}
+}
+
+// isBoolFlag reports whether a flag is given without a value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// commandNames returns the names of the public subcommands in order.
+func commandNames() []string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatNames returns the names of the built-in output formats in order.
+func formatNames() []string {
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// programName is the name the program was invoked as, which the completion scripts
+// register for.
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxDecompressedSize bounds how much a compressed input file may expand to, so a
+// malicious archive cannot exhaust memory.
+const maxDecompressedSize = 1 << 30
+
+// decompressInput returns the contents of a gzip-compressed input file, or of the
+// bookmark file inside a zip archive such as a Google Takeout export. Other files are
+// returned as they are.
+func decompressInput(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x1f\x8b")):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing input: %w", err)
+		}
+		defer zr.Close()
+		return readLimited(zr)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return readArchive(data)
+	}
+	return data, nil
+}
+
+// readArchive returns the contents of the bookmark file in a zip archive: the file named
+// like a bookmarks export, or else the only file whose contents are in a known format.
+func readArchive(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading zip archive: %w", err)
+	}
+
+	var best *zip.File
+	bestRank := 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if rank := archiveEntryRank(f.Name); rank > bestRank {
+			best, bestRank = f, rank
+		}
+	}
+	if best != nil {
+		return readArchiveEntry(best)
+	}
+
+	// fall back to the contents of the files when no name gives the export away.
+	var found []byte
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		contents, err := readArchiveEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		if !isBookmarkExport(contents) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("error reading zip archive: more than one bookmark file found")
+		}
+		found = contents
+	}
+	if found == nil {
+		return nil, fmt.Errorf("error reading zip archive: no bookmark file found")
+	}
+	return found, nil
+}
+
+// archiveEntryRank scores how likely an archive entry is the bookmarks export by its name,
+// with 0 meaning the name says nothing.
+func archiveEntryRank(name string) int {
+	base := strings.ToLower(path.Base(name))
+	ext := path.Ext(base)
+	if !strings.Contains(base, "bookmark") {
+		return 0
+	}
+	switch ext {
+	case ".html", ".htm":
+		return 3
+	case ".json", ".xml", ".csv":
+		return 2
+	}
+	return 1
+}
+
+// isBookmarkExport reports whether data is in one of the input formats, HTML exports being
+// recognized by their bookmark list.
+func isBookmarkExport(data []byte) bool {
+	if bytes.Contains(bytes.ToUpper(data), []byte("<DL")) {
+		return true
+	}
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	for _, imp := range importers {
+		if imp.detect(head) {
+			return true
+		}
+	}
+	return false
+}
+
+// readArchiveEntry returns the decompressed contents of a zip archive entry.
+func readArchiveEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s from zip archive: %w", f.Name, err)
+	}
+	defer rc.Close()
+	return readLimited(rc)
+}
+
+// readLimited reads all of r, failing when it holds more than maxDecompressedSize bytes.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing input: %w", err)
+	}
+	if len(data) > maxDecompressedSize {
+		return nil, fmt.Errorf("error decompressing input: larger than %d bytes", maxDecompressedSize)
+	}
+	return data, nil
+}
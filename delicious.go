@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// deliciousPosts is the document of a Delicious XML export, as returned by its
+// posts/all API: a flat list of links without folders.
+type deliciousPosts struct {
+	Posts []struct {
+		Href        string `xml:"href,attr"`
+		Description string `xml:"description,attr"` // the link's title.
+		Extended    string `xml:"extended,attr"`    // the link's notes.
+		Tag         string `xml:"tag,attr"`         // space-separated tags.
+		Time        string `xml:"time,attr"`
+		Shared      string `xml:"shared,attr"`
+	} `xml:"post"`
+}
+
+// isDeliciousXML reports whether an export starts like a Delicious XML document.
+func isDeliciousXML(head []byte) bool {
+	return bytes.Contains(head, []byte("<posts"))
+}
+
+// parseDeliciousXML parses a Delicious XML export into a root folder titled rootTitle
+// holding every post.
+func parseDeliciousXML(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	var doc deliciousPosts
+	if err := xml.NewDecoder(contextReader{ctx, r}).Decode(&doc); err != nil {
+		return Bookmark{}, fmt.Errorf("error parsing XML: %w", err)
+	}
+
+	root := Bookmark{Title: rootTitle}
+	for _, post := range doc.Posts {
+		b := Bookmark{
+			Title:       post.Description,
+			URL:         post.Href,
+			Tags:        strings.Fields(post.Tag),
+			Description: post.Extended,
+			Private:     post.Shared == "no",
+		}
+		if t, err := time.Parse(time.RFC3339, post.Time); err == nil {
+			b.AddAt = &t
+		}
+		root.Bookmarks = append(root.Bookmarks, b)
+	}
+	return root, nil
+}
+
+// parseTags splits the comma-separated TAGS attribute of a Delicious HTML export.
+func parseTags(attr string) []string {
+	var tags []string
+	for _, tag := range strings.Split(attr, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// maxDiffCells bounds the table used to align the changed middle of two renderings.
+// Larger changes are shown as removing every old line and adding every new one.
+const maxDiffCells = 4 << 20
+
+// diffOp is a line of a diff: kept (' '), removed ('-'), or added ('+'). a and b are the
+// numbers of lines of the old and new rendering preceding it.
+type diffOp struct {
+	kind byte
+	line string
+	a, b int
+}
+
+// treeLines renders the tree one entry per line, indented by depth, with the entry's
+// fields other than its children as JSON. Diffing two renderings shows every change.
+func treeLines(root *Bookmark) []string {
+	line := func(b *Bookmark, depth int) string {
+		fields := *b
+		fields.Bookmarks = nil
+		data, _ := json.Marshal(fields)
+		return strings.Repeat("  ", depth) + string(data)
+	}
+	lines := []string{line(root, 0)}
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		lines = append(lines, line(b, len(path)))
+	})
+	return lines
+}
+
+// writeTreeDiff writes the changes from the rendering before of a tree to the tree as it
+// is now as a unified diff, writing nothing when they are the same.
+func writeTreeDiff(w io.Writer, name string, before []string, after *Bookmark) {
+	ops := diffLines(before, treeLines(after))
+	header := false
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// a hunk runs until the changes are far enough apart to need separate context.
+		from, end := max(i-diffContext, 0), i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		to := min(end+diffContext, len(ops))
+
+		if !header {
+			fmt.Fprintf(w, "--- %s\n+++ %s\n", name, name)
+			header = true
+		}
+		removed, added := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				removed++
+			}
+			if op.kind != '-' {
+				added++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", ops[from].a+1, removed, ops[from].b+1, added)
+		for _, op := range ops[from:to] {
+			fmt.Fprintf(w, "%c%s\n", op.kind, op.line)
+		}
+		i = to
+	}
+}
+
+// diffLines aligns two sequences of lines by their longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// lines shared at both ends need no alignment.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var ops []diffOp
+	emit := func(kind byte, line string, i, j int) {
+		ops = append(ops, diffOp{kind: kind, line: line, a: i, b: j})
+	}
+	for i := 0; i < prefix; i++ {
+		emit(' ', a[i], i, i)
+	}
+
+	n, m := len(midA), len(midB)
+	if (n+1)*(m+1) > maxDiffCells {
+		for i, line := range midA {
+			emit('-', line, prefix+i, prefix)
+		}
+		for j, line := range midB {
+			emit('+', line, prefix+n, prefix+j)
+		}
+	} else {
+		// lcs[i][j] is the length of the common subsequence of midA[i:] and midB[j:].
+		lcs := make([][]int32, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				emit(' ', midA[i], prefix+i, prefix+j)
+				i, j = i+1, j+1
+			case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+				emit('-', midA[i], prefix+i, prefix+j)
+				i++
+			default:
+				emit('+', midB[j], prefix+i, prefix+j)
+				j++
+			}
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		emit(' ', a[len(a)-suffix+k], len(a)-suffix+k, len(b)-suffix+k)
+	}
+	return ops
+}
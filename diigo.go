@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// diigoTimeLayouts are the layouts seen in the created_at column of Diigo exports.
+var diigoTimeLayouts = []string{
+	"2006/01/02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// isDiigoCSV reports whether an export starts with the header row of a Diigo CSV export.
+func isDiigoCSV(head []byte) bool {
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	line = bytes.ToLower(bytes.TrimPrefix(line, []byte("\xef\xbb\xbf")))
+	return bytes.HasPrefix(line, []byte("title,url,")) && bytes.Contains(line, []byte("tags"))
+}
+
+// parseDiigoCSV parses a Diigo CSV export into a root folder titled rootTitle holding
+// every link. Columns are found by their header, so only title and url are required.
+func parseDiigoCSV(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	reader := csv.NewReader(contextReader{ctx, r})
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("error parsing CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return Bookmark{}, fmt.Errorf("error parsing CSV: missing header")
+	}
+
+	// map the header to column indexes.
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return Bookmark{}, fmt.Errorf("error parsing CSV: missing url column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	root := Bookmark{Title: rootTitle}
+	for _, record := range records[1:] {
+		b := Bookmark{
+			Title:       field(record, "title"),
+			URL:         field(record, "url"),
+			Tags:        parseDiigoTags(field(record, "tags")),
+			Description: field(record, "description"),
+		}
+		if b.URL == "" {
+			continue
+		}
+		for _, layout := range diigoTimeLayouts {
+			if t, err := time.Parse(layout, field(record, "created_at")); err == nil {
+				b.AddAt = &t
+				break
+			}
+		}
+		root.Bookmarks = append(root.Bookmarks, b)
+	}
+	return root, nil
+}
+
+// parseDiigoTags splits the tags column, which separates tags with commas or spaces and
+// holds no_tag for untagged links.
+func parseDiigoTags(column string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(column, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if tag != "no_tag" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// disambiguateTitles renames entries sharing a title with a sibling, so that outputs
+// addressing entries by path, such as the flat formats or per-folder exports, tell them
+// apart. With the suffix style the first entry keeps its title and the others get " (2)",
+// " (3)", and so on; with the index style every one of them is numbered. Numbers already
+// taken by a sibling are skipped. Each collision is reported on w, and the number of
+// renamed entries is returned.
+func disambiguateTitles(root *Bookmark, style string, w io.Writer) (int, error) {
+	if style != "suffix" && style != "index" {
+		return 0, fmt.Errorf("unknown disambiguation style %q", style)
+	}
+	renamed := 0
+	var walk func(folder *Bookmark, path []string)
+	walk = func(folder *Bookmark, path []string) {
+		path = append(path, folder.Title)
+
+		// group the entries by title, keeping the order titles first appear in.
+		var titles []string
+		entries := make(map[string][]*Bookmark)
+		for i := range folder.Bookmarks {
+			b := &folder.Bookmarks[i]
+			if entries[b.Title] == nil {
+				titles = append(titles, b.Title)
+			}
+			entries[b.Title] = append(entries[b.Title], b)
+		}
+		for _, title := range titles {
+			group := entries[title]
+			if len(group) == 1 {
+				continue
+			}
+			fmt.Fprintf(w, "%d entries titled %q in %s\n", len(group), title, folderPath(path))
+			n := 1
+			if style == "suffix" {
+				group, n = group[1:], 2
+			}
+			for _, b := range group {
+				for entries[title+" ("+strconv.Itoa(n)+")"] != nil {
+					n++
+				}
+				b.Title = title + " (" + strconv.Itoa(n) + ")"
+				entries[b.Title] = []*Bookmark{b}
+				renamed++
+				n++
+			}
+		}
+
+		for i := range folder.Bookmarks {
+			if folder.Bookmarks[i].isFolder() {
+				walk(&folder.Bookmarks[i], path)
+			}
+		}
+	}
+	walk(root, nil)
+	return renamed, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// loadDomainList reads a file of host patterns, one per line. Blank lines and lines
+// starting with # are skipped. The list is never nil, so an empty file allows no hosts.
+func loadDomainList(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading domain list: %w", err)
+	}
+	patterns := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		pattern = strings.TrimPrefix(pattern, ".")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("error parsing domain list: %s:%d: %w", file, line, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, scanner.Err()
+}
+
+// matchesDomain reports whether host matches any of the patterns. A plain pattern matches
+// the domain and its subdomains, while one with wildcards, such as *.corp.example.com or
+// intranet-*, is matched against the whole host.
+func matchesDomain(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "*?[") {
+			if ok, _ := path.Match(pattern, host); ok {
+				return true
+			}
+		} else if host == pattern || strings.HasSuffix(host, "."+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterDomains removes the links whose host is not on the allow list, unless that is
+// nil, or is on the deny list. Links without a host, such as bookmarklets, are kept. It
+// returns the number of links removed.
+func filterDomains(root *Bookmark, allow, deny []string) int {
+	removed := 0
+	removeBookmarks(root, func(b *Bookmark) bool {
+		if b.isFolder() {
+			return false
+		}
+		u, err := url.Parse(b.URL)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		if allow != nil && !matchesDomain(allow, host) || matchesDomain(deny, host) {
+			removed++
+			return true
+		}
+		return false
+	})
+	return removed
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// mobileLabels are host labels marking the mobile version of a site, as in m.example.com
+// or en.m.wikipedia.org.
+var mobileLabels = map[string]bool{"m": true, "mobile": true}
+
+// duplicateGroup holds the links considered the same page.
+type duplicateGroup struct {
+	key     string
+	members []*Bookmark
+	paths   []string // folder path of each member.
+}
+
+// runDupes implements the dupes subcommand, reporting links to the same page and
+// optionally merging them. With -titles it instead reports links whose titles are alike,
+// as when the same article was saved from different aggregators.
+func runDupes(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	merge := fs.String("merge", "", "keep one link of each group and remove the others: first, oldest, or newest")
+	output := addOutputFlags(fs, "write the merged tree as JSON to this file instead of stdout")
+	dryRun := fs.Bool("dry-run", false, "print the changes -merge would make as a diff instead of writing the tree")
+	titles := fs.Bool("titles", false, "report clusters of links with similar titles instead of duplicate URLs")
+	threshold := fs.Float64("threshold", 0.8, "similarity from 0 to 1 above which -titles clusters two titles")
+	return func(ctx context.Context) error {
+		var pick func(group *duplicateGroup) *Bookmark
+		switch *merge {
+		case "":
+		case "first":
+			pick = func(group *duplicateGroup) *Bookmark { return group.members[0] }
+		case "oldest", "newest":
+			pick = func(group *duplicateGroup) *Bookmark {
+				kept := group.members[0]
+				for _, b := range group.members[1:] {
+					if b.AddAt == nil || kept.AddAt != nil && (*merge == "oldest") == !b.AddAt.Before(*kept.AddAt) {
+						continue
+					}
+					kept = b
+				}
+				return kept
+			}
+		default:
+			return fmt.Errorf("unknown merge policy %q", *merge)
+		}
+
+		if *titles && pick != nil {
+			return fmt.Errorf("-merge cannot be combined with -titles")
+		}
+
+		tree, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		if *titles {
+			writeTitleReport(os.Stdout, findSimilarTitles(&tree, *threshold))
+			return nil
+		}
+		groups := findDuplicates(&tree)
+
+		// without a merge policy the report is the output.
+		if pick == nil {
+			writeDuplicateReport(os.Stdout, groups)
+			return nil
+		}
+		writeDuplicateReport(os.Stderr, groups)
+
+		before := treeLines(&tree)
+		drop := make(map[*Bookmark]bool)
+		for _, group := range groups {
+			kept := pick(group)
+			for _, b := range group.members {
+				drop[b] = b != kept
+				if b != kept {
+					absorbDuplicate(kept, b)
+					currentReport.count("deduped", 1)
+				}
+			}
+		}
+		removeBookmarks(&tree, func(b *Bookmark) bool { return drop[b] })
+
+		switch {
+		case *dryRun:
+			writeTreeDiff(os.Stdout, inputPath(fs), before, &tree)
+			return nil
+		case *output.path != "":
+			currentReport.output(*output.path, &tree)
+			return output.save(ctx, func(w io.Writer) error { return writeJSON(w, &tree) })
+		default:
+			currentReport.output("", &tree)
+			return writeJSON(os.Stdout, &tree)
+		}
+	}
+}
+
+// findDuplicates groups the web links of the tree that point to the same page, in the
+// order their first links appear.
+func findDuplicates(root *Bookmark) []*duplicateGroup {
+	var groups []*duplicateGroup
+	byKey := make(map[string]*duplicateGroup)
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() || !isWebURL(b.URL) {
+			return
+		}
+		key, _ := canonicalURL(b.URL)
+		group, ok := byKey[key]
+		if !ok {
+			group = &duplicateGroup{key: key}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.members = append(group.members, b)
+		group.paths = append(group.paths, folderPath(path))
+	})
+
+	duplicates := groups[:0]
+	for _, group := range groups {
+		if len(group.members) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates
+}
+
+// absorbDuplicate gives the link kept of a group the tags and description of a duplicate
+// about to be dropped, so that merging loses none of them.
+func absorbDuplicate(kept, dup *Bookmark) {
+	for _, tag := range dup.Tags {
+		if !containsString(kept.Tags, tag) {
+			kept.Tags = append(kept.Tags, tag)
+		}
+	}
+	switch {
+	case dup.Description == "" || strings.Contains(kept.Description, dup.Description):
+	case kept.Description == "":
+		kept.Description = dup.Description
+	default:
+		kept.Description += "\n\n" + dup.Description
+	}
+}
+
+// canonicalURL returns the key under which URLs of the same page compare equal, along with
+// the differences from it that the URL had: the scheme, a www or mobile subdomain, a
+// trailing slash, a fragment, or being an AMP version of the page. Fragments holding a
+// client-side route, such as #/inbox or #!/inbox, address distinct pages and are kept.
+func canonicalURL(rawURL string) (string, []string) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL, nil
+	}
+	var traits []string
+	if amp, ok := canonicalAMP(u); ok {
+		u = amp
+		traits = append(traits, "amp")
+	}
+	if u.Scheme == "http" {
+		traits = append(traits, "http")
+	}
+
+	labels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	if len(labels) > 2 && (labels[0] == "www" || strings.HasPrefix(labels[0], "www") && strings.Trim(labels[0][3:], "0123456789") == "") {
+		labels = labels[1:]
+		traits = append(traits, "www")
+	}
+	for i := 0; i < len(labels)-2; i++ {
+		if mobileLabels[labels[i]] {
+			labels = append(labels[:i:i], labels[i+1:]...)
+			traits = append(traits, "mobile")
+			break
+		}
+	}
+
+	path := u.EscapedPath()
+	if strings.HasSuffix(path, "/") {
+		path = strings.TrimSuffix(path, "/")
+		traits = append(traits, "trailing slash")
+	}
+	isRoute := strings.HasPrefix(u.Fragment, "/") || strings.HasPrefix(u.Fragment, "!")
+	if u.Fragment != "" && !isRoute {
+		traits = append(traits, "fragment")
+	}
+	key := strings.Join(labels, ".")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		key += ":" + port
+	}
+	key += path
+	if u.RawQuery != "" {
+		query := u.Query()
+		key += "?" + query.Encode()
+	}
+	if isRoute {
+		key += "#" + u.Fragment
+	}
+	return key, traits
+}
+
+// writeDuplicateReport writes each group of duplicates, listing for every link after the
+// first how its URL differs from the first one's.
+func writeDuplicateReport(w io.Writer, groups []*duplicateGroup) {
+	total := 0
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s (%d links)\n", group.key, len(group.members))
+		_, first := canonicalURL(group.members[0].URL)
+		for i, b := range group.members {
+			fmt.Fprintf(w, "\t%s\t%s\t%s", group.paths[i], b.Title, b.URL)
+			if i > 0 {
+				_, traits := canonicalURL(b.URL)
+				fmt.Fprintf(w, "\t%s", urlDifferences(b.URL, group.members[0].URL, first, traits))
+			}
+			fmt.Fprintln(w)
+		}
+		total += len(group.members) - 1
+	}
+	fmt.Fprintf(w, "%d groups, %d duplicate links\n", len(groups), total)
+}
+
+// urlDifferences describes how a URL differs from the first of its group, given the
+// traits canonicalURL found in each.
+func urlDifferences(rawURL, firstURL string, first, traits []string) string {
+	if rawURL == firstURL {
+		return "exact"
+	}
+	count := make(map[string]int)
+	for _, trait := range first {
+		count[trait]++
+	}
+	for _, trait := range traits {
+		count[trait]--
+	}
+	var diffs []string
+	for trait, n := range count {
+		if n != 0 {
+			diffs = append(diffs, trait)
+		}
+	}
+	if len(diffs) == 0 {
+		return "case or query order"
+	}
+	sort.Strings(diffs)
+	return strings.Join(diffs, ", ")
+}
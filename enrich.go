@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Enricher adds derived data to a bookmark. Enrichers run concurrently on different
+// bookmarks, so implementations must be safe for concurrent use.
+type Enricher interface {
+	Enrich(ctx context.Context, b *Bookmark) error
+}
+
+// enricherPlugin describes an enrichment that can be switched on from the command line
+// with a flag named after it.
+type enricherPlugin struct {
+	name  string
+	usage string
+	// cacheable marks enrichers whose network lookups may be served from the on-disk cache.
+	cacheable bool
+	// setup registers the enricher's own options on fs and returns the constructor to
+	// call once flags are parsed.
+	setup func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error)
+}
+
+// enricherPlugins holds the registered enrichments in the order they run.
+var enricherPlugins []enricherPlugin
+
+// registerEnricher makes an enrichment available to the enrich subcommand. It is meant
+// to be called from the init function of the file implementing the enricher.
+func registerEnricher(plugin enricherPlugin) {
+	enricherPlugins = append(enricherPlugins, plugin)
+}
+
+// runEnrich implements the enrich subcommand, adding derived fields to every bookmark.
+func runEnrich(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	workers := fs.Int("workers", 8, "number of bookmarks enriched concurrently")
+	timeout := fs.Duration("timeout", 15*time.Second, "timeout for each network request")
+	cacheDir := fs.String("cache-dir", defaultCacheDir(), "directory caching network lookups between runs")
+	cacheTTL := fs.Duration("cache-ttl", 7*24*time.Hour, "how long cached lookups stay valid (0 disables the cache)")
+	statePath := fs.String("state", "", "state file used to only enrich bookmarks that are new or changed since the last run")
+
+	// give every registered enricher an enable flag and its own options, remembering which
+	// flags are whose.
+	enabled := make([]*bool, len(enricherPlugins))
+	constructors := make([]func(client *http.Client) (Enricher, error), len(enricherPlugins))
+	options := make([][]string, len(enricherPlugins))
+	for i, plugin := range enricherPlugins {
+		enabled[i] = fs.Bool(plugin.name, false, plugin.usage)
+		known := make(map[string]bool)
+		fs.VisitAll(func(f *flag.Flag) { known[f.Name] = true })
+		constructors[i] = plugin.setup(fs)
+		fs.VisitAll(func(f *flag.Flag) {
+			if !known[f.Name] {
+				options[i] = append(options[i], f.Name)
+			}
+		})
+	}
+	return func(ctx context.Context) error {
+		if *workers < 1 {
+			return fmt.Errorf("-workers must be at least 1")
+		}
+
+		client := &http.Client{Timeout: *timeout}
+		var enrichers []Enricher
+		var names []string
+		for i := range enricherPlugins {
+			if !*enabled[i] {
+				continue
+			}
+			values := make([]string, len(options[i]))
+			for j, name := range options[i] {
+				values[j] = name + "=" + fs.Lookup(name).Value.String()
+			}
+			optionValues := strings.Join(values, ",")
+			names = append(names, enricherPlugins[i].name+"("+optionValues+")")
+			enricher, err := constructors[i](client)
+			if err != nil {
+				return fmt.Errorf("error setting up %s: %w", enricherPlugins[i].name, err)
+			}
+			if enricherPlugins[i].cacheable && *cacheDir != "" && *cacheTTL > 0 {
+				if enricher, err = newCachedEnricher(enricherPlugins[i].name, optionValues, *cacheDir, *cacheTTL, enricher); err != nil {
+					return fmt.Errorf("error creating cache: %w", err)
+				}
+			}
+			enrichers = append(enrichers, enricher)
+		}
+		if len(enrichers) == 0 {
+			return fmt.Errorf("no enrichers enabled")
+		}
+
+		var state *runState
+		if *statePath != "" {
+			var err error
+			if state, err = loadRunState(*statePath, "enrich "+strings.Join(names, " ")); err != nil {
+				return err
+			}
+		}
+
+		tree, err := load(ctx)
+		if err != nil {
+			return err
+		}
+
+		// an interrupted run still saves and prints what was enriched so far.
+		enrichTree(ctx, &tree, enrichers, *workers, state)
+		if err := state.save(); err != nil {
+			return err
+		}
+		if err := writeJSON(os.Stdout, &tree); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+}
+
+// enrichTree runs the enrichers over every web bookmark in the tree using a pool of
+// workers. Failures are reported and do not stop the remaining enrichments. Bookmarks
+// unchanged since the run that produced state get its results instead of being enriched.
+// Once ctx is done, no further bookmarks are enriched.
+func enrichTree(ctx context.Context, root *Bookmark, enrichers []Enricher, workers int, state *runState) {
+	jobs := make(chan *Bookmark)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				hash := bookmarkHash(b)
+				if entry, ok := state.lookup(hash); ok {
+					overlayFields(b, entry.Fields)
+					state.record(hash, entry)
+					continue
+				}
+
+				// enrich the bookmark, recording what changed unless something failed. The
+				// enrichers share the pages they fetch.
+				before, _ := bookmarkFields(b)
+				failed := false
+				pageCtx := context.WithValue(ctx, fetchedPagesKey{}, make(fetchedPages))
+				for _, enricher := range enrichers {
+					if err := enricher.Enrich(pageCtx, b); err != nil {
+						// failures caused by cancellation are not worth reporting.
+						if ctx.Err() == nil {
+							warnf("error enriching %q: %s", b.URL, err.Error())
+						}
+						failed = true
+					}
+				}
+				if changed, err := changedFields(before, b); err == nil && !failed {
+					state.record(hash, stateEntry{Fields: changed})
+				}
+			}
+		}()
+	}
+
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if !b.isFolder() && isWebURL(b.URL) {
+			select {
+			case jobs <- b:
+			case <-ctx.Done():
+			}
+		}
+	})
+	close(jobs)
+	wg.Wait()
+}
+
+// fetchedPagesKey is the context key of the pages fetched while enriching a bookmark.
+type fetchedPagesKey struct{}
+
+// fetchedPages maps the URLs of pages fetched while enriching a bookmark to the result,
+// so enrichers reading the same page download it once. Callers must not modify the
+// documents.
+type fetchedPages map[string]fetchedPage
+
+type fetchedPage struct {
+	doc *goquery.Document
+	err error
+}
+
+// fetchPage downloads and parses an HTML page, following redirects. Within enrichTree,
+// a page fetched for a bookmark by one enricher is reused by the others.
+func fetchPage(ctx context.Context, client *http.Client, url string) (*goquery.Document, error) {
+	pages, _ := ctx.Value(fetchedPagesKey{}).(fetchedPages)
+	if page, ok := pages[url]; ok {
+		return page.doc, page.err
+	}
+	doc, err := downloadPage(ctx, client, url)
+	if pages != nil && ctx.Err() == nil {
+		pages[url] = fetchedPage{doc, err}
+	}
+	return doc, err
+}
+
+// downloadPage implements fetchPage.
+func downloadPage(ctx context.Context, client *http.Client, url string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// remember where the page ended up so relative links resolve correctly.
+	doc.Url = resp.Request.URL
+	return doc, nil
+}
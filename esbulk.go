@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// esBulkAction is the action line preceding every document of a bulk request.
+type esBulkAction struct {
+	Index struct {
+		ID string `json:"_id"`
+	} `json:"index"`
+}
+
+// writeESBulk writes the links of the tree as an Elasticsearch or OpenSearch bulk request
+// body, indexing every flattened link as a document. The index is named by the URL the
+// body is posted to, as in
+//
+//	curl -H 'Content-Type: application/x-ndjson' --data-binary @bookmarks.ndjson localhost:9200/bookmarks/_bulk
+//
+// Document IDs hash the folder path and URL, so indexing a later export again updates
+// links in place rather than duplicating them.
+func writeESBulk(w io.Writer, tree *Bookmark) error {
+	return writeESBulkRows(w, flattenBookmarks(tree))
+}
+
+// writeESBulkRows writes the bulk request body of writeESBulk for the given records.
+func writeESBulkRows(w io.Writer, rows []flatBookmark) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	seen := make(map[string]int)
+	for _, row := range rows {
+		// links repeated in a folder are told apart by their occurrence.
+		key := row.Path + "\t" + row.URL
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key += fmt.Sprintf("#%d", n)
+		}
+		sum := sha256.Sum256([]byte(key))
+
+		var action esBulkAction
+		action.Index.ID = hex.EncodeToString(sum[:16])
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
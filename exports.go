@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// export writes the folders matching a pattern to a file of their own.
+type export struct {
+	Folder string `json:"folder"` // slash separated path below the root, with * wildcards.
+	Output string `json:"output"` // file or cloud storage URI to write.
+	Format string `json:"format"` // output format, by default named by the output's extension.
+
+	write func(w io.Writer, tree *Bookmark) error
+}
+
+// loadExports reads an exports file, a JSON array of exports, and looks up the writer of
+// each so an unknown format is reported before anything is written.
+func loadExports(file string) ([]export, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading exports: %w", err)
+	}
+	var exports []export
+	if err := json.Unmarshal(data, &exports); err != nil {
+		return nil, fmt.Errorf("error parsing exports: %w", err)
+	}
+	for i := range exports {
+		e := &exports[i]
+		e.Folder = strings.Trim(e.Folder, "/")
+		if e.Folder == "" || e.Output == "" {
+			return nil, fmt.Errorf("error parsing exports: export %d needs a folder and an output", i+1)
+		}
+		if _, err := path.Match(e.Folder, ""); err != nil {
+			return nil, fmt.Errorf("error parsing exports: export %d: %w", i+1, err)
+		}
+		if e.Format == "" {
+			e.Format = strings.TrimPrefix(filepath.Ext(e.Output), ".")
+		}
+		var ok bool
+		if e.write, ok = formats[e.Format]; !ok {
+			if e.write, ok = outputPlugin(e.Format); !ok {
+				return nil, fmt.Errorf("error parsing exports: export %d: unknown format %q", i+1, e.Format)
+			}
+		}
+	}
+	return exports, nil
+}
+
+// matchFolders returns the folders below root whose path matches the pattern, leaving
+// out those inside a folder already matched.
+func matchFolders(root *Bookmark, pattern string) []*Bookmark {
+	var matched []*Bookmark
+	var walk func(parent *Bookmark, titles []string)
+	walk = func(parent *Bookmark, titles []string) {
+		for i := range parent.Bookmarks {
+			child := &parent.Bookmarks[i]
+			if !child.isFolder() {
+				continue
+			}
+			childPath := append(titles[:len(titles):len(titles)], child.Title)
+			if ok, _ := path.Match(pattern, folderPath(childPath)); ok {
+				matched = append(matched, child)
+			} else {
+				walk(child, childPath)
+			}
+		}
+	}
+	walk(root, nil)
+	return matched
+}
+
+// selectFolders returns the tree holding the folders below root whose path matches the
+// pattern: a single matching folder becomes its root, while several are gathered under a
+// copy of root. It returns nil when no folder matches.
+func selectFolders(root *Bookmark, pattern string) *Bookmark {
+	folders := matchFolders(root, pattern)
+	switch len(folders) {
+	case 0:
+		return nil
+	case 1:
+		return folders[0]
+	}
+	tree := &Bookmark{Title: root.Title, AddAt: root.AddAt, UpdateAt: root.UpdateAt}
+	for _, folder := range folders {
+		tree.Bookmarks = append(tree.Bookmarks, *folder)
+	}
+	return tree
+}
+
+// writeExports writes every export, through output's backup and atomic replacement.
+// The tree of each export is selected by selectFolders. Exports matching no folder are
+// reported and skipped.
+func writeExports(ctx context.Context, root *Bookmark, exports []export, output *outputFlags) error {
+	for _, e := range exports {
+		tree := selectFolders(root, e.Folder)
+		if tree == nil {
+			warnf("no folder matches %q, not writing %s", e.Folder, e.Output)
+			continue
+		}
+		dest := *output
+		dest.path = &e.Output
+		if err := dest.save(ctx, func(w io.Writer) error { return e.write(w, tree) }); err != nil {
+			return fmt.Errorf("error writing %s: %w", e.Output, err)
+		}
+		currentReport.output(e.Output, tree)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxFaviconSize bounds the icons embedded into the output.
+const maxFaviconSize = 64 << 10
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:      "favicon",
+		usage:     "fetch each site's icon and embed it as a data URI",
+		cacheable: true,
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			return func(client *http.Client) (Enricher, error) {
+				return &faviconEnricher{client: client}, nil
+			}
+		},
+	})
+}
+
+// faviconEnricher fills in the Icon field of bookmarks that have none.
+type faviconEnricher struct {
+	client *http.Client
+}
+
+func (e *faviconEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	if b.Icon != "" {
+		return nil
+	}
+
+	// use the icon the page links to, falling back to /favicon.ico.
+	iconURL, err := url.Parse(b.URL)
+	if err != nil {
+		return err
+	}
+	iconURL = iconURL.ResolveReference(&url.URL{Path: "/favicon.ico"})
+	if doc, err := fetchPage(ctx, e.client, b.URL); err == nil {
+		href, ok := doc.Find(`link[rel~="icon"]`).First().Attr("href")
+		if resolved, err := doc.Url.Parse(href); ok && err == nil {
+			iconURL = resolved
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("favicon returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFaviconSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxFaviconSize {
+		return fmt.Errorf("favicon larger than %d bytes", maxFaviconSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	b.Icon = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	return nil
+}
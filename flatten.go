@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// flatBookmark is a link along with the folder holding it, the record written by flat outputs.
+type flatBookmark struct {
+	Path     string     `json:"path" parquet:"path,dict"`
+	Title    string     `json:"title" parquet:"title"`
+	URL      string     `json:"url" parquet:"url"`
+	Domain   string     `json:"domain" parquet:"domain,dict"`
+	AddAt    *time.Time `json:"addAt,omitempty" parquet:"add_at,optional"`
+	UpdateAt *time.Time `json:"updateAt,omitempty" parquet:"update_at,optional"`
+
+	Tags        []string `json:"tags,omitempty" parquet:"tags,list"`
+	Description string   `json:"description,omitempty" parquet:"description"`
+}
+
+// flattenBookmarks returns every link of the tree in document order.
+func flattenBookmarks(root *Bookmark) []flatBookmark {
+	var rows []flatBookmark
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() {
+			return
+		}
+		rows = append(rows, flatten(b, path))
+	})
+	return rows
+}
+
+// flatten returns the record of the link held by the folders on path.
+func flatten(b *Bookmark, path []string) flatBookmark {
+	return flatBookmark{
+		Path:     folderPath(path),
+		Title:    b.Title,
+		URL:      b.URL,
+		Domain:   domainOf(b.URL),
+		AddAt:    b.AddAt,
+		UpdateAt: b.UpdateAt,
+
+		Tags:        b.Tags,
+		Description: b.Description,
+	}
+}
+
+// pageRows returns at most limit of the records after skipping offset of them, or all
+// those after offset when limit is 0. With newest, records are ordered by when their link
+// was added, newest first, and those without a date come last.
+func pageRows(rows []flatBookmark, offset, limit int, newest bool) []flatBookmark {
+	if newest {
+		sort.SliceStable(rows, func(i, j int) bool {
+			a, b := rows[i].AddAt, rows[j].AddAt
+			return a != nil && (b == nil || a.After(*b))
+		})
+	}
+	rows = rows[min(offset, len(rows)):]
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// domainOf returns the lowercased host of the URL without a leading "www.".
+func domainOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzParse checks the guarantees documented on WithLenient, and that the streaming
+// parser handles the same input, starting from the sample exports:
+//
+//	go test -fuzz FuzzParse
+func FuzzParse(f *testing.F) {
+	fixtures, err := filepath.Glob("bookmarks_test*.html")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range fixtures {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree, err := Parse(context.Background(), bytes.NewReader(data), WithLenient(), WithIcons())
+		if err != nil {
+			t.Fatalf("lenient parse failed: %v", err)
+		}
+		if err := writeJSON(io.Discard, &tree); err != nil {
+			t.Fatalf("tree cannot be written as JSON: %v", err)
+		}
+		ParseStream(context.Background(), bytes.NewReader(data), func(Event) error { return nil })
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+
+	"github.com/onntztzf/parse-bookmarks/bookmarkspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer implements the BookmarksService defined in bookmarkspb/bookmarks.proto.
+type grpcServer struct {
+	bookmarkspb.UnimplementedBookmarksServiceServer
+}
+
+// runGRPC implements the grpc subcommand, serving the parser over gRPC.
+func runGRPC(fs *flag.FlagSet) func(ctx context.Context) error {
+	addr := fs.String("addr", ":50051", "address to listen on")
+	metricsAddr := fs.String("metrics-addr", "", "address to expose Prometheus metrics on")
+	return func(ctx context.Context) error {
+		if *metricsAddr != "" {
+			serveMetrics(*metricsAddr)
+		}
+
+		listener, err := net.Listen("tcp", *addr)
+		if err != nil {
+			return fmt.Errorf("error listening: %w", err)
+		}
+		server := grpc.NewServer(grpc.UnaryInterceptor(instrumentGRPC))
+		bookmarkspb.RegisterBookmarksServiceServer(server, &grpcServer{})
+		fmt.Printf("serving gRPC on %s\n", listener.Addr())
+
+		// stop accepting calls when interrupted, letting those in flight finish.
+		go func() {
+			<-ctx.Done()
+			server.GracefulStop()
+		}()
+		return server.Serve(listener)
+	}
+}
+
+func (s *grpcServer) Parse(ctx context.Context, req *bookmarkspb.ParseRequest) (*bookmarkspb.ParseResponse, error) {
+	tree, err := parseHTML(ctx, bytes.NewReader(req.GetHtml()), defaultRootTitle)
+	observeParse(&tree, err)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &bookmarkspb.ParseResponse{Tree: toProto(&tree)}, nil
+}
+
+func (s *grpcServer) Convert(ctx context.Context, req *bookmarkspb.ConvertRequest) (*bookmarkspb.ConvertResponse, error) {
+	write, ok := formats[req.GetFormat()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown format %q", req.GetFormat())
+	}
+	tree, err := parseHTML(ctx, bytes.NewReader(req.GetHtml()), defaultRootTitle)
+	observeParse(&tree, err)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var output bytes.Buffer
+	if err := write(&output, &tree); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &bookmarkspb.ConvertResponse{Output: output.Bytes()}, nil
+}
+
+func (s *grpcServer) Search(ctx context.Context, req *bookmarkspb.SearchRequest) (*bookmarkspb.SearchResponse, error) {
+	tree, err := parseHTML(ctx, bytes.NewReader(req.GetHtml()), defaultRootTitle)
+	observeParse(&tree, err)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &bookmarkspb.SearchResponse{}
+	for _, result := range searchBookmarks(&tree, req.GetQuery(), int(req.GetLimit())) {
+		resp.Results = append(resp.Results, &bookmarkspb.SearchResult{
+			Bookmark: toProto(result.Bookmark),
+			Folder:   result.Folder,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// importer reads bookmark exports of a service other than a browser.
+type importer struct {
+	name string
+	// detect reports whether a file starting with head is in the importer's format.
+	detect func(head []byte) bool
+	parse  func(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error)
+}
+
+// importers are tried in order when detecting the format of an input file. Files none
+// of them recognize are read as browser HTML exports, which includes the Delicious HTML
+// dialect.
+var importers = []importer{
+	{name: "json", detect: isTreeJSON, parse: parseTreeJSON},
+	{name: "delicious-xml", detect: isDeliciousXML, parse: parseDeliciousXML},
+	{name: "diigo-csv", detect: isDiigoCSV, parse: parseDiigoCSV},
+	{name: "onetab", detect: isOneTab, parse: parseOneTab},
+	{name: "session-json", detect: isSessionJSON, parse: parseSessionJSON},
+}
+
+// importerNames lists the accepted input format names.
+func importerNames() []string {
+	names := []string{"html"}
+	for _, imp := range importers {
+		names = append(names, imp.name)
+	}
+	return names
+}
+
+// findImporter returns the parser for the named input format, or for the format data is
+// detected to be in when format is empty. HTML exports are parsed with opts. The parser
+// names a synthesized root rootTitle, or when it is empty, after the HTML export's H1
+// title or defaultRootTitle.
+func findImporter(format string, data []byte, opts ...Option) (func(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error), error) {
+	html := func(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+		opts := append([]Option{WithLenient()}, opts...)
+		if rootTitle != "" {
+			opts = append(opts, WithRootTitle(rootTitle))
+		}
+		result, err := ParseResult(ctx, r, opts...)
+		printWarnings(result.Warnings)
+		return result.Tree, err
+	}
+	if format == "html" {
+		return html, nil
+	}
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	for _, imp := range importers {
+		if imp.name == format || format == "" && imp.detect(head) {
+			// only HTML exports have a title of their own to fall back to.
+			parse := imp.parse
+			return func(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+				if rootTitle == "" {
+					rootTitle = defaultRootTitle
+				}
+				return parse(ctx, r, rootTitle)
+			}, nil
+		}
+	}
+	if format != "" {
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	return html, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// isTreeJSON reports whether an export starts like the JSON written by this tool.
+func isTreeJSON(head []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(head), []byte(`{"title":`))
+}
+
+// parseTreeJSON reads a bookmark tree written by the json format, so that its output can
+// be processed again.
+func parseTreeJSON(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	var tree Bookmark
+	if err := json.NewDecoder(contextReader{ctx, r}).Decode(&tree); err != nil {
+		return Bookmark{}, fmt.Errorf("error parsing JSON: %w", err)
+	}
+	return tree, nil
+}
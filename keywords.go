@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// titleWeight is how many times the words of a title count compared to those of the page
+// text, as titles are written to say what a page is about.
+const titleWeight = 3
+
+// maxPageText bounds how much of each fetched page is used for keywords.
+const maxPageText = 64 << 10
+
+// extraStopwords are English words frequent in titles that make poor tags, beyond the
+// stopwords used to detect languages.
+var extraStopwords = []string{
+	"a", "an", "are", "as", "at", "be", "by", "can", "from", "has", "have", "it", "its",
+	"new", "not", "or", "our", "that", "this", "was", "we", "why", "will", "you", "home",
+	"page", "http", "https", "www", "com", "html",
+}
+
+// runKeywords implements the keywords subcommand, which suggests tags for every link from
+// the words that set its title, description, and optionally its page apart from the rest
+// of the export.
+func runKeywords(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	count := fs.Int("n", 3, "number of tags suggested for each link")
+	fetch := fs.Bool("fetch", false, "fetch each page and include its text")
+	workers := fs.Int("workers", 8, "number of pages fetched concurrently with -fetch")
+	timeout := fs.Duration("timeout", 15*time.Second, "timeout for each page request with -fetch")
+	apply := fs.Bool("apply", false, "add the suggestions to each link's tags and print the tree as JSON instead of a report")
+	return func(ctx context.Context) error {
+		if *workers < 1 {
+			return fmt.Errorf("-workers must be at least 1")
+		}
+
+		tree, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		var links []*Bookmark
+		var paths []string
+		walkBookmarks(&tree, func(b *Bookmark, path []string) {
+			if !b.isFolder() {
+				links = append(links, b)
+				paths = append(paths, folderPath(path))
+			}
+		})
+
+		// collect the text of every link.
+		texts := make([]string, len(links))
+		for i, b := range links {
+			texts[i] = strings.Repeat(b.Title+" ", titleWeight) + b.Description
+		}
+		if *fetch {
+			client := &http.Client{Timeout: *timeout}
+			fetchTexts(ctx, client, links, texts, *workers)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		suggestions := suggestKeywords(texts, *count)
+		if !*apply {
+			for i, b := range links {
+				if len(suggestions[i]) > 0 {
+					fmt.Printf("%s\t%s\t%s\t%s\n", paths[i], b.Title, b.URL, strings.Join(suggestions[i], ","))
+				}
+			}
+			return nil
+		}
+		for i, b := range links {
+			for _, tag := range suggestions[i] {
+				if !containsString(b.Tags, tag) {
+					b.Tags = append(b.Tags, tag)
+				}
+			}
+		}
+		return writeJSON(os.Stdout, &tree)
+	}
+}
+
+// fetchTexts appends the visible text of every web link's page to its entry in texts,
+// using a pool of workers. Pages that fail to load are reported and skipped.
+func fetchTexts(ctx context.Context, client *http.Client, links []*Bookmark, texts []string, workers int) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				doc, err := fetchPage(ctx, client, links[i].URL)
+				if err != nil {
+					if ctx.Err() == nil {
+						warnf("error fetching %q: %s", links[i].URL, err.Error())
+					}
+					continue
+				}
+				doc.Find("script, style, noscript").Remove()
+				text := doc.Find("body").Text()
+				if len(text) > maxPageText {
+					text = text[:maxPageText]
+					for !utf8.ValidString(text) {
+						text = text[:len(text)-1]
+					}
+				}
+				texts[i] += " " + text
+			}
+		}()
+	}
+	for i, b := range links {
+		if !isWebURL(b.URL) {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// stopwordSet returns the stopwords of every language, and the extra ones.
+func stopwordSet() map[string]bool {
+	stop := make(map[string]bool)
+	for _, list := range stopwords {
+		for _, word := range list {
+			stop[word] = true
+		}
+	}
+	for _, word := range extraStopwords {
+		stop[word] = true
+	}
+	return stop
+}
+
+// suggestKeywords returns up to n keywords for each text, ranked by TF-IDF. Words found in
+// only one text, or in every text, tell nothing about how texts group and are skipped.
+func suggestKeywords(texts []string, n int) [][]string {
+	stop := stopwordSet()
+
+	// count the words of every text, and the texts every word appears in.
+	counts := make([]map[string]int, len(texts))
+	totals := make([]int, len(texts))
+	df := make(map[string]int)
+	for i, text := range texts {
+		counts[i] = make(map[string]int)
+		for _, word := range titleWords(text) {
+			if stop[word] || utf8.RuneCountInString(word) < 3 || strings.Trim(word, "0123456789") == "" {
+				continue
+			}
+			if counts[i][word] == 0 {
+				df[word]++
+			}
+			counts[i][word]++
+			totals[i]++
+		}
+	}
+
+	suggestions := make([][]string, len(texts))
+	for i := range texts {
+		type scored struct {
+			word  string
+			score float64
+		}
+		var ranked []scored
+		for word, count := range counts[i] {
+			if df[word] < 2 || df[word] == len(texts) {
+				continue
+			}
+			tf := float64(count) / float64(totals[i])
+			idf := math.Log(float64(len(texts)) / float64(df[word]))
+			ranked = append(ranked, scored{word, tf * idf})
+		}
+		sort.Slice(ranked, func(a, b int) bool {
+			if ranked[a].score != ranked[b].score {
+				return ranked[a].score > ranked[b].score
+			}
+			return ranked[a].word < ranked[b].word
+		})
+		for j := 0; j < len(ranked) && j < n; j++ {
+			suggestions[i] = append(suggestions[i], ranked[j].word)
+		}
+	}
+	return suggestions
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// scriptLanguages maps Unicode scripts used by a single dominant language to its code.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Cyrillic, "ru"},
+}
+
+// stopwords holds frequent short words used to tell Latin-script languages apart.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "for", "is", "on", "with", "how", "what", "your", "you"},
+	"de": {"der", "die", "das", "und", "ist", "mit", "für", "ein", "eine", "nicht", "von", "zu", "wie"},
+	"fr": {"le", "la", "les", "et", "des", "du", "un", "une", "est", "pour", "dans", "avec", "sur"},
+	"es": {"el", "los", "las", "y", "del", "un", "una", "es", "para", "con", "por", "como", "que"},
+	"it": {"il", "lo", "gli", "e", "di", "del", "della", "un", "una", "è", "per", "con", "che"},
+	"pt": {"o", "os", "as", "e", "do", "da", "um", "uma", "é", "para", "com", "não", "que"},
+	"nl": {"de", "het", "een", "en", "van", "is", "voor", "met", "op", "niet", "hoe", "wat"},
+	"sv": {"och", "att", "det", "som", "en", "är", "för", "med", "på", "inte", "av", "hur"},
+}
+
+// detectLanguage guesses the ISO 639-1 code of the text's language, returning "" when
+// it cannot tell. Non-Latin scripts are recognized by their characters, Latin-script
+// languages by counting common stopwords.
+func detectLanguage(text string) string {
+	// count the letters of each script; kana outweighs Han since Japanese mixes both.
+	counts := make(map[string]int)
+	for _, r := range text {
+		for _, script := range scriptLanguages {
+			if unicode.Is(script.table, r) {
+				counts[script.lang]++
+				break
+			}
+		}
+	}
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for lang, count := range counts {
+		if count > bestCount {
+			best, bestCount = lang, count
+		}
+	}
+	if best == "ru" && strings.ContainsAny(text, "іїєґІЇЄҐ") {
+		return "uk"
+	}
+	if best != "" {
+		return best
+	}
+
+	// score the Latin-script candidates by stopword hits.
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	best, bestCount = "", 0
+	for lang, list := range stopwords {
+		count := 0
+		for _, word := range words {
+			for _, stopword := range list {
+				if word == stopword {
+					count++
+					break
+				}
+			}
+		}
+		if count > bestCount || (count == bestCount && count > 0 && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+	return best
+}
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:  "lang",
+		usage: "detect the language of each title",
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			fetch := fs.Bool("lang-fetch", false, "fetch the page when the title's language is inconclusive")
+			return func(client *http.Client) (Enricher, error) {
+				return &langEnricher{client: client, fetch: *fetch}, nil
+			}
+		},
+	})
+}
+
+// langEnricher stores the detected language of each bookmark in its Lang field.
+type langEnricher struct {
+	client *http.Client
+	fetch  bool
+}
+
+func (e *langEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	if b.Lang = detectLanguage(b.Title); b.Lang != "" || !e.fetch {
+		return nil
+	}
+
+	doc, err := fetchPage(ctx, e.client, b.URL)
+	if err != nil {
+		return err
+	}
+	b.Lang = pageLanguage(doc)
+	return nil
+}
+
+// pageLanguage returns the page's declared language, falling back to detecting the
+// language of its text.
+func pageLanguage(doc *goquery.Document) string {
+	// prefer the primary subtag of <html lang>, such as "en" from "en-US".
+	if lang := strings.TrimSpace(doc.Find("html").AttrOr("lang", "")); lang != "" {
+		return strings.ToLower(strings.SplitN(lang, "-", 2)[0])
+	}
+	text := doc.Find("title").Text() + " " + doc.Find("body").Text()
+	if len(text) > 4096 {
+		text = text[:4096]
+	}
+	return detectLanguage(text)
+}
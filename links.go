@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:      "status",
+		usage:     "record each URL's HTTP status, content type, and final URL",
+		cacheable: true,
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			follow := fs.Bool("follow-redirects", true, "follow redirect chains when recording the status")
+			return func(client *http.Client) (Enricher, error) {
+				return newLinkChecker(client.Timeout, *follow), nil
+			}
+		},
+	})
+}
+
+// linkChecker fetches each bookmark's URL and records the response metadata on it.
+type linkChecker struct {
+	client *http.Client
+}
+
+// newLinkChecker creates a link checker. Unless follow is set, redirects are not followed
+// and the final URL recorded is the first redirect's target.
+func newLinkChecker(timeout time.Duration, follow bool) *linkChecker {
+	client := &http.Client{Timeout: timeout}
+	if !follow {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return &linkChecker{client: client}
+}
+
+// check fetches the bookmark, storing the HTTP status, content type, and final URL after
+// redirects, and reports unreachable or failing links.
+func (c *linkChecker) check(ctx context.Context, b *Bookmark) (string, error) {
+	if !isWebURL(b.URL) {
+		return "", nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.URL, nil)
+	if err != nil {
+		return "invalid URL: " + err.Error(), nil
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// a cancelled check says nothing about the link.
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		observeLinkCheck(0)
+		return "unreachable: " + err.Error(), nil
+	}
+	defer resp.Body.Close()
+	observeLinkCheck(resp.StatusCode)
+	// drain a bounded amount of the body so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	b.Status = resp.StatusCode
+	b.ContentType = resp.Header.Get("Content-Type")
+	if final := resp.Request.URL.String(); final != b.URL {
+		b.FinalURL = final
+	} else if location, err := resp.Location(); err == nil {
+		b.FinalURL = location.String()
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Sprintf("http %d", resp.StatusCode), nil
+	}
+	return "", nil
+}
+
+// Enrich records the response metadata on the bookmark, failing if the URL is unreachable.
+func (c *linkChecker) Enrich(ctx context.Context, b *Bookmark) error {
+	problem, err := c.check(ctx, b)
+	if err == nil && strings.HasPrefix(problem, "unreachable: ") {
+		err = errors.New(problem)
+	}
+	return err
+}
+
+// rewriteRedirects replaces the URL of every bookmark that redirected to a working page
+// with its final destination, printing each change. It returns the number of rewrites.
+func rewriteRedirects(root *Bookmark) int {
+	rewritten := 0
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.FinalURL == "" || b.Status >= 300 {
+			return
+		}
+		fmt.Printf("rewrite\t%s\t%s\t%s\n", folderPath(path), b.URL, b.FinalURL)
+		b.URL, b.FinalURL = b.FinalURL, ""
+		rewritten++
+	})
+	return rewritten
+}
+
+// isWebURL reports whether the URL can be fetched over HTTP.
+func isWebURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
@@ -0,0 +1,45 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	// cancel the running command on the first interrupt; a second one exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// dispatch to a subcommand when the first argument names one.
+	if len(os.Args) > 1 {
+		if os.Args[1] == completeCommand {
+			exit(runComplete(ctx, os.Args[2:]))
+			return
+		}
+		if _, ok := commands[os.Args[1]]; ok {
+			exit(runCommand(ctx, os.Args[1], os.Args[2:]))
+			return
+		}
+	}
+
+	// otherwise convert the input file to JSON.
+	exit(runCommand(ctx, "convert", os.Args[1:]))
+}
+
+// exit writes the run report, if one was asked for, and exits when the command failed.
+func exit(err error) {
+	if reportErr := currentReport.finish(err); reportErr != nil && err == nil {
+		err = reportErr
+	}
+	if err != nil {
+		fmt.Printf("error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
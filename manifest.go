@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// manifestEntry records one link of an export in a manifest.
+type manifestEntry struct {
+	Folder string `json:"folder"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Hash   string `json:"hash"` // SHA-256 of the link's fields.
+}
+
+// manifest maps the identity of every link, its folder and URL, to its entry.
+type manifest map[string]manifestEntry
+
+// buildManifest records every link of the tree. Links repeated within a folder get an
+// occurrence number in their identity so each one is tracked.
+func buildManifest(root *Bookmark) manifest {
+	m := make(manifest)
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() {
+			return
+		}
+		entry := manifestEntry{Folder: folderPath(path), Title: b.Title, URL: b.URL, Hash: contentHash(b)}
+		key := entry.Folder + "\t" + entry.URL
+		for n := 2; ; n++ {
+			if _, ok := m[key]; !ok {
+				break
+			}
+			key = entry.Folder + "\t" + entry.URL + "#" + strconv.Itoa(n)
+		}
+		m[key] = entry
+	})
+	return m
+}
+
+// contentHash hashes every field of a bookmark other than its entries.
+func contentHash(b *Bookmark) string {
+	fields := *b
+	fields.Bookmarks = nil
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadManifest reads the manifest at path; a missing file returns a nil manifest.
+func loadManifest(path string) (manifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %w", err)
+	}
+	return m, nil
+}
+
+// save writes the manifest to path.
+func (m manifest) save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+	return nil
+}
+
+// manifestChange is a link added, removed, or modified between two manifests.
+type manifestChange struct {
+	Kind string `json:"kind"` // added, removed, or modified.
+	manifestEntry
+}
+
+// manifestChanges lists the links added or modified since the previous manifest, followed
+// by the links removed, each in order of their identity.
+func manifestChanges(previous, current manifest) []manifestChange {
+	var changes []manifestChange
+	for _, key := range sortedKeys(current) {
+		entry := current[key]
+		old, ok := previous[key]
+		switch {
+		case !ok:
+			changes = append(changes, manifestChange{"added", entry})
+		case old.Hash != entry.Hash:
+			changes = append(changes, manifestChange{"modified", entry})
+		}
+	}
+	for _, key := range sortedKeys(previous) {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, manifestChange{"removed", previous[key]})
+		}
+	}
+	return changes
+}
+
+// changeSummary counts changes by kind, as in "2 added, 1 removed, 0 modified".
+func changeSummary(changes []manifestChange) string {
+	count := make(map[string]int)
+	for _, change := range changes {
+		count[change.Kind]++
+	}
+	return fmt.Sprintf("%d added, %d removed, %d modified", count["added"], count["removed"], count["modified"])
+}
+
+// reportChanges writes a line for every link added, removed, or modified since the
+// previous manifest, followed by a summary.
+func reportChanges(w io.Writer, previous, current manifest) {
+	changes := manifestChanges(previous, current)
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Kind, change.Folder, change.Title, change.URL)
+	}
+	fmt.Fprintln(w, changeSummary(changes))
+}
+
+// sortedKeys returns the identities in a manifest in order.
+func sortedKeys(m manifest) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mergeInput is an input of the merge subcommand.
+type mergeInput struct {
+	name string
+	path string
+	tree Bookmark
+}
+
+// runMerge implements the merge subcommand, combining several exports into one tree.
+// Folders with the same path are merged, special folders lining up across browsers and
+// locales, and a link whose URL appears in several inputs is kept once, from the input
+// the -prefer policy picks. Every link records its source.
+func runMerge(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFileFlags(fs)
+	format := fs.String("format", "json", "output format (cbor, esbulk, html, json, msgpack, ndjson, org, parquet, pb, pbjson, toml, or an output plugin name)")
+	output := addOutputFlags(fs, "write the merged tree to this file instead of stdout")
+	prefer := fs.String("prefer", "first", "which input's title, folder, and timestamps a link found in several inputs gets: first, newest, oldest, source=<name>, or interactive")
+	var removeSources stringList
+	fs.Var(&removeSources, "remove-source", "drop the links a previous merge took from the named source (repeatable)")
+	return func(ctx context.Context) error {
+		if fs.NArg() == 0 {
+			return fmt.Errorf("merge needs at least one input file, given as path or name=path")
+		}
+		pick, err := mergePolicy(*prefer)
+		if err != nil {
+			return err
+		}
+		write, ok := formats[*format]
+		if !ok {
+			if write, ok = outputPlugin(*format); !ok {
+				return fmt.Errorf("unknown format %q", *format)
+			}
+		}
+
+		// name each input after its file unless given a name. Links are told apart by source
+		// name, so every input needs a distinct one.
+		var inputs []mergeInput
+		names := make(map[string]bool)
+		for _, arg := range fs.Args() {
+			name, path, ok := strings.Cut(arg, "=")
+			if !ok || isRemoteInput(arg) || isCloudURI(arg) {
+				name, path = strings.TrimSuffix(filepath.Base(arg), filepath.Ext(arg)), arg
+			}
+			if names[name] {
+				return fmt.Errorf("several inputs are named %q; name them with name=path", name)
+			}
+			names[name] = true
+			inputs = append(inputs, mergeInput{name: name, path: path})
+		}
+		if name, ok := strings.CutPrefix(*prefer, "source="); ok && !names[name] {
+			return fmt.Errorf("-prefer names unknown source %q", name)
+		}
+
+		// read the inputs.
+		for i := range inputs {
+			if inputs[i].tree, err = load(ctx, inputs[i].path); err != nil {
+				return fmt.Errorf("error reading %s: %w", inputs[i].path, err)
+			}
+		}
+
+		tree := mergeTrees(inputs, pick)
+		for _, input := range inputs {
+			currentReport.count("deduped", countLinks(&input.tree))
+		}
+		currentReport.count("deduped", -countLinks(&tree))
+		if len(removeSources) > 0 {
+			before := countLinks(&tree)
+			removeBookmarks(&tree, func(b *Bookmark) bool {
+				return b.Source != nil && containsString(removeSources, b.Source.Name)
+			})
+			currentReport.count("dropped", before-countLinks(&tree))
+		}
+		currentReport.output(*output.path, &tree)
+		if *output.path != "" {
+			return output.save(ctx, func(w io.Writer) error { return write(w, &tree) })
+		}
+		return write(os.Stdout, &tree)
+	}
+}
+
+// mergePick chooses among the first links with the same URL in each of several inputs,
+// given in input order, returning the index of the one to keep.
+type mergePick func(candidates []*Bookmark) int
+
+// mergePolicy returns the mergePick implementing a -prefer policy.
+func mergePolicy(prefer string) (mergePick, error) {
+	switch prefer {
+	case "first":
+		return func(candidates []*Bookmark) int { return 0 }, nil
+	case "newest", "oldest":
+		// newest compares when links were last active and oldest when they were added;
+		// links without timestamps lose to those with them.
+		when := lastActivity
+		if prefer == "oldest" {
+			when = func(b *Bookmark) *time.Time { return b.AddAt }
+		}
+		return func(candidates []*Bookmark) int {
+			best, bestTime := 0, when(candidates[0])
+			for i := 1; i < len(candidates); i++ {
+				t := when(candidates[i])
+				if t != nil && (bestTime == nil || prefer == "newest" && t.After(*bestTime) || prefer == "oldest" && t.Before(*bestTime)) {
+					best, bestTime = i, t
+				}
+			}
+			return best
+		}, nil
+	case "interactive":
+		stdin := bufio.NewReader(os.Stdin)
+		return func(candidates []*Bookmark) int { return askMergePick(stdin, candidates) }, nil
+	}
+	if name, ok := strings.CutPrefix(prefer, "source="); ok && name != "" {
+		return func(candidates []*Bookmark) int {
+			for i, b := range candidates {
+				if b.Source != nil && b.Source.Name == name {
+					return i
+				}
+			}
+			return 0
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown merge policy %q", prefer)
+}
+
+// askMergePick lists the candidates on stderr and reads the number of the one to keep
+// from stdin, keeping the first on an empty answer or at the end of the input.
+func askMergePick(stdin *bufio.Reader, candidates []*Bookmark) int {
+	fmt.Fprintf(os.Stderr, "%s is in %d inputs:\n", candidates[0].URL, len(candidates))
+	for i, b := range candidates {
+		fmt.Fprintf(os.Stderr, "  %d) %s\t%s\t%s\n", i+1, b.Source.Name, b.Source.Folder, b.Title)
+	}
+	for {
+		fmt.Fprintf(os.Stderr, "keep [1-%d, default 1]: ", len(candidates))
+		line, err := stdin.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			if err != nil {
+				fmt.Fprintln(os.Stderr)
+			}
+			return 0
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(candidates) {
+			return n - 1
+		}
+		if err != nil {
+			return 0
+		}
+	}
+}
+
+// mergeTrees combines the inputs, keeping the links of every URL found in several inputs
+// from the one pick chooses. Links without a source, which have not been merged before,
+// get one naming their input and folder there.
+func mergeTrees(inputs []mergeInput, pick mergePick) Bookmark {
+	for i := range inputs {
+		input := &inputs[i]
+		walkBookmarks(&input.tree, func(b *Bookmark, path []string) {
+			if !b.isFolder() && b.Source == nil {
+				b.Source = &Source{Name: input.name, Path: input.path, Folder: folderPath(path)}
+			}
+		})
+		// an export of a single special folder uses it as the root, which is merged with
+		// that folder in the other inputs instead.
+		normalizeSpecialFolders(&input.tree, true)
+		if input.tree.Special != "" {
+			input.tree = Bookmark{Title: defaultRootTitle, Bookmarks: []Bookmark{input.tree}}
+		}
+	}
+
+	// collect the first link of every URL in each input, and pick the input to keep it
+	// from where there is more than one.
+	var urls []string
+	candidates := make(map[string][]*Bookmark)
+	inputsOf := make(map[string][]int)
+	for i := range inputs {
+		walkBookmarks(&inputs[i].tree, func(b *Bookmark, path []string) {
+			if b.isFolder() {
+				return
+			}
+			found := inputsOf[b.URL]
+			if len(found) > 0 && found[len(found)-1] == i {
+				return
+			}
+			if len(found) == 0 {
+				urls = append(urls, b.URL)
+			}
+			candidates[b.URL] = append(candidates[b.URL], b)
+			inputsOf[b.URL] = append(found, i)
+		})
+	}
+	keptFrom := make(map[string]int, len(urls))
+	for _, url := range urls {
+		choice := 0
+		if len(candidates[url]) > 1 {
+			choice = pick(candidates[url])
+		}
+		keptFrom[url] = inputsOf[url][choice]
+	}
+
+	merged := Bookmark{Title: inputs[0].tree.Title, AddAt: inputs[0].tree.AddAt, UpdateAt: inputs[0].tree.UpdateAt}
+	for i := range inputs {
+		walkBookmarks(&inputs[i].tree, func(b *Bookmark, path []string) {
+			if !b.isFolder() && keptFrom[b.URL] != i {
+				return
+			}
+			// folders are walked before their entries, so the first input holding a
+			// folder decides its fields.
+			parent := ensureFolder(&merged, path[1:])
+			if b.isFolder() {
+				for _, child := range parent.Bookmarks {
+					if child.isFolder() && child.Title == b.Title {
+						return
+					}
+				}
+			}
+			entry := *b
+			entry.Bookmarks = nil
+			parent.Bookmarks = append(parent.Bookmarks, entry)
+		})
+	}
+	return merged
+}
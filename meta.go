@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:      "meta",
+		usage:     "record each page's meta description and Open Graph title and image",
+		cacheable: true,
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			return func(client *http.Client) (Enricher, error) {
+				return &metaEnricher{client: client}, nil
+			}
+		},
+	})
+}
+
+// metaEnricher records the description and Open Graph preview pages declare for
+// themselves. Pages without a meta description fall back to their og:description.
+type metaEnricher struct {
+	client *http.Client
+}
+
+func (e *metaEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	doc, err := fetchPage(ctx, e.client, b.URL)
+	if err != nil {
+		return err
+	}
+	b.MetaDescription = metaContent(doc, `meta[name="description" i]`)
+	if b.MetaDescription == "" {
+		b.MetaDescription = metaContent(doc, `meta[property="og:description"]`)
+	}
+	b.OGTitle = metaContent(doc, `meta[property="og:title"]`)
+	b.OGImage = metaContent(doc, `meta[property="og:image"]`)
+	// image URLs may be relative to the page.
+	if b.OGImage != "" && doc.Url != nil {
+		if image, err := doc.Url.Parse(b.OGImage); err == nil {
+			b.OGImage = image.String()
+		}
+	}
+	return nil
+}
+
+// metaContent returns the trimmed content of the first meta element matching selector.
+func metaContent(doc *goquery.Document, selector string) string {
+	return strings.Join(strings.Fields(doc.Find(selector).First().AttrOr("content", "")), " ")
+}
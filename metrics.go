@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	bookmarksParsed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "parse_bookmarks_parsed_total",
+		Help: "Number of bookmarks and folders parsed from exports.",
+	})
+	parseErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "parse_bookmarks_parse_errors_total",
+		Help: "Number of exports that failed to parse.",
+	})
+	linkChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "parse_bookmarks_link_checks_total",
+		Help: "Number of link checks by HTTP status class (2xx, 3xx, 4xx, 5xx, or error).",
+	}, []string{"class"})
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "parse_bookmarks_request_duration_seconds",
+		Help:    "Latency of HTTP and gRPC requests served.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+)
+
+// observeParse records the outcome of parsing one export.
+func observeParse(tree *Bookmark, err error) {
+	if err != nil {
+		parseErrors.Inc()
+		return
+	}
+	count := 1
+	walkBookmarks(tree, func(b *Bookmark, path []string) {
+		count++
+	})
+	bookmarksParsed.Add(float64(count))
+}
+
+// observeLinkCheck records the status class of a link check; a zero status means the
+// link was unreachable.
+func observeLinkCheck(status int) {
+	class := "error"
+	if status > 0 {
+		class = strconv.Itoa(status/100) + "xx"
+	}
+	linkChecks.WithLabelValues(class).Inc()
+}
+
+// serveMetrics exposes /metrics on addr in the background, for modes that do not run
+// an HTTP server of their own.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Fprintf(os.Stderr, "error serving metrics: %s\n", err.Error())
+		}
+	}()
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// instrumentHTTP records the latency of each request, labelled by its route pattern
+// rather than its path to keep the number of series bounded.
+func instrumentHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		requestDuration.WithLabelValues(r.Method, r.Pattern, strconv.Itoa(recorder.status)).Observe(time.Since(start).Seconds())
+	})
+}
+
+// instrumentGRPC records the latency of each unary gRPC call.
+func instrumentGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	requestDuration.WithLabelValues("grpc", info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return resp, err
+}
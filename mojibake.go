@@ -0,0 +1,55 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// maxMojibakeRounds bounds how many layers of double encoding are undone, as text that
+// went through a misconfigured tool more than once is encoded again each time.
+const maxMojibakeRounds = 3
+
+// fixMojibake repairs the title and description of b when they hold UTF-8 text that was
+// decoded as Windows-1252 or Latin-1 and saved as UTF-8 again, turning "Ã¤" back into "ä".
+func fixMojibake(b *Bookmark) {
+	b.Title = repairMojibake(b.Title)
+	b.Description = repairMojibake(b.Description)
+}
+
+// repairMojibake undoes double encoding in s. Text is only changed when all of it encodes
+// to Windows-1252 and the resulting bytes are valid UTF-8 with at least one multi-byte
+// sequence, which correctly decoded Latin text practically never is.
+func repairMojibake(s string) string {
+	for i := 0; i < maxMojibakeRounds; i++ {
+		repaired, ok := undoLatin1(s)
+		if !ok {
+			break
+		}
+		s = repaired
+	}
+	return s
+}
+
+// undoLatin1 encodes s as Windows-1252, falling back to Latin-1 for the code points
+// Windows-1252 leaves undefined, and returns the bytes if they are UTF-8 text that
+// differs from s.
+func undoLatin1(s string) (string, bool) {
+	encoded := make([]byte, 0, len(s))
+	multiByte := false
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			if r > 0xff {
+				return "", false
+			}
+			c = byte(r)
+		}
+		multiByte = multiByte || c >= utf8.RuneSelf
+		encoded = append(encoded, c)
+	}
+	if !multiByte || !utf8.Valid(encoded) {
+		return "", false
+	}
+	return string(encoded), true
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// writeNDJSON writes the links of the tree as newline-delimited JSON, one flat record per
+// line.
+func writeNDJSON(w io.Writer, tree *Bookmark) error {
+	return writeNDJSONRows(w, flattenBookmarks(tree))
+}
+
+// writeNDJSONRows writes the records of writeNDJSON.
+func writeNDJSONRows(w io.Writer, rows []flatBookmark) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// runStream implements the stream subcommand, writing the links of an HTML export as
+// newline-delimited JSON while it is parsed. Records match those of the ndjson format,
+// but the tree is never built, so memory use does not grow with the export and output
+// starts right away. Without the tree, paths start with -root-title rather than the
+// title the export gives its root. Gzip-compressed exports are decompressed on the fly.
+func runStream(fs *flag.FlagSet) func(ctx context.Context) error {
+	rootTitle := fs.String("root-title", defaultRootTitle, "title of the root folder starting every path")
+	output := addOutputFlags(fs, "write the records to this file instead of stdout")
+	return func(ctx context.Context) error {
+		file, err := os.Open(inputPath(fs))
+		if err != nil {
+			return fmt.Errorf("error reading file: %w", err)
+		}
+		defer file.Close()
+		input := bufio.NewReader(file)
+		var r io.Reader = input
+		if magic, _ := input.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+			gz, err := gzip.NewReader(input)
+			if err != nil {
+				return fmt.Errorf("error decompressing input: %w", err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		stream := func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			enc := json.NewEncoder(bw)
+			err := ParseStream(ctx, r, func(e Event) error {
+				if e.Kind == EventWarning {
+					warnf("%s", e.Warning)
+				}
+				if e.Kind != EventBookmark {
+					return nil
+				}
+				return enc.Encode(flatten(&e.Bookmark, append([]string{*rootTitle}, e.Path...)))
+			})
+			if err != nil {
+				return err
+			}
+			return bw.Flush()
+		}
+		if *output.path != "" {
+			return output.save(ctx, stream)
+		}
+		return stream(os.Stdout)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// linkAttrs and folderAttrs are the attributes of links and folders in HTML exports that
+// the parser interprets; any others are kept in Extra. ICON goes into the Icon field when
+// icons are kept, per WithIcons, and is dropped otherwise.
+var (
+	linkAttrs   = map[string]bool{"href": true, "add_date": true, "last_modified": true, "tags": true, "private": true, "icon": true}
+	folderAttrs = map[string]bool{"add_date": true, "last_modified": true, "personal_toolbar_folder": true, "unfiled_bookmarks_folder": true}
+)
+
+// extraAttrs returns the attributes not in known, or nil when there are none.
+func extraAttrs(attrs map[string]string, known map[string]bool) map[string]string {
+	var extra map[string]string
+	for key, value := range attrs {
+		if known[key] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]string)
+		}
+		extra[key] = value
+	}
+	return extra
+}
+
+// attrsOf returns the attributes of the element s holds.
+func attrsOf(s *goquery.Selection) map[string]string {
+	attrs := make(map[string]string)
+	if node := s.Get(0); node != nil {
+		for _, attr := range node.Attr {
+			attrs[attr.Key] = attr.Val
+		}
+	}
+	return attrs
+}
+
+// writeHTML writes the tree as a Netscape bookmark file, the HTML format browsers import
+// and export. Attributes kept in Extra are written back after the ones the parser
+// interprets, so converting an export to JSON and back loses nothing.
+func writeHTML(w io.Writer, tree *Bookmark) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n"+
+		"<!-- This is an automatically generated file.\n"+
+		"     It will be read and overwritten.\n"+
+		"     DO NOT EDIT! -->\n"+
+		"<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n"+
+		"<TITLE>Bookmarks</TITLE>\n")
+	// a root with attributes is the only top-level folder of the export it was read from,
+	// which is written as such since the H1 title holds no attributes.
+	if tree.AddAt != nil || tree.UpdateAt != nil || tree.Special != "" || len(tree.Extra) > 0 {
+		fmt.Fprintf(bw, "<H1>%s</H1>\n", defaultRootTitle)
+		writeHTMLFolder(bw, &Bookmark{Bookmarks: []Bookmark{*tree}}, 0)
+	} else {
+		fmt.Fprintf(bw, "<H1>%s</H1>\n", escapeHTML(tree.Title))
+		writeHTMLFolder(bw, tree, 0)
+	}
+	return bw.Flush()
+}
+
+// writeHTMLFolder writes the entries of folder as a DL list indented by depth levels.
+func writeHTMLFolder(w *bufio.Writer, folder *Bookmark, depth int) {
+	indent := strings.Repeat("    ", depth)
+	fmt.Fprintf(w, "%s<DL><p>\n", indent)
+	for i := range folder.Bookmarks {
+		b := &folder.Bookmarks[i]
+		var attrs strings.Builder
+		if !b.isFolder() {
+			writeHTMLAttr(&attrs, "href", b.URL)
+		}
+		writeHTMLTime(&attrs, "add_date", b.AddAt)
+		writeHTMLTime(&attrs, "last_modified", b.UpdateAt)
+		if b.isFolder() {
+			switch b.Special {
+			case specialToolbar:
+				writeHTMLAttr(&attrs, "personal_toolbar_folder", "true")
+			case specialOther:
+				writeHTMLAttr(&attrs, "unfiled_bookmarks_folder", "true")
+			}
+		} else {
+			if b.Icon != "" {
+				writeHTMLAttr(&attrs, "icon", b.Icon)
+			}
+			if len(b.Tags) > 0 {
+				writeHTMLAttr(&attrs, "tags", strings.Join(b.Tags, ","))
+			}
+			if b.Private {
+				writeHTMLAttr(&attrs, "private", "1")
+			}
+		}
+		keys := make([]string, 0, len(b.Extra))
+		for key := range b.Extra {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			writeHTMLAttr(&attrs, key, b.Extra[key])
+		}
+
+		if b.isFolder() {
+			fmt.Fprintf(w, "%s    <DT><H3%s>%s</H3>\n", indent, attrs.String(), escapeHTML(b.Title))
+			writeHTMLFolder(w, b, depth+1)
+			continue
+		}
+		fmt.Fprintf(w, "%s    <DT><A%s>%s</A>\n", indent, attrs.String(), escapeHTML(b.Title))
+		if b.Description != "" {
+			fmt.Fprintf(w, "%s    <DD>%s\n", indent, escapeHTML(b.Description))
+		}
+	}
+	fmt.Fprintf(w, "%s</DL><p>\n", indent)
+}
+
+// writeHTMLAttr appends an attribute with an uppercase name, as browsers write them.
+func writeHTMLAttr(attrs *strings.Builder, name, value string) {
+	fmt.Fprintf(attrs, " %s=\"%s\"", strings.ToUpper(name), escapeHTML(value))
+}
+
+// writeHTMLTime appends a timestamp attribute holding Unix seconds, unless t is nil.
+func writeHTMLTime(attrs *strings.Builder, name string, t *time.Time) {
+	if t != nil {
+		writeHTMLAttr(attrs, name, strconv.FormatInt(t.Unix(), 10))
+	}
+}
+
+// htmlEscaper escapes text and attribute values. Carriage returns are written as
+// character references, which parsers keep, rather than as they are, which parsers turn
+// into newlines; this keeps multi-line bookmarklets byte for byte.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\r", "&#13;")
+
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	notionPagesURL = "https://api.notion.com/v1/pages"
+	notionVersion  = "2022-06-28"
+
+	// notionMaxRetries bounds how often a rate-limited request is retried.
+	notionMaxRetries = 6
+)
+
+// notionExporter pushes bookmarks into a Notion database, one page per bookmark.
+type notionExporter struct {
+	token      string
+	databaseID string
+	client     *http.Client
+	limiter    <-chan time.Time // throttles requests to stay under the API rate limit.
+	state      *os.File         // records pushed bookmarks so an interrupted run can resume.
+	done       map[string]bool
+}
+
+// runNotion implements the notion subcommand.
+func runNotion(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	token := fs.String("token", os.Getenv("NOTION_TOKEN"), "Notion integration token (defaults to $NOTION_TOKEN)")
+	databaseID := fs.String("database", "", "ID of the Notion database to push into")
+	statePath := fs.String("state", "notion-state.txt", "file recording already pushed bookmarks, used to resume")
+	rate := fs.Duration("rate", 350*time.Millisecond, "minimum interval between API requests")
+	return func(ctx context.Context) error {
+		if *token == "" || *databaseID == "" {
+			return fmt.Errorf("both -token and -database are required")
+		}
+		if *rate <= 0 {
+			return fmt.Errorf("-rate must be positive")
+		}
+
+		tree, err := load(ctx)
+		if err != nil {
+			return err
+		}
+
+		exporter, err := newNotionExporter(*token, *databaseID, *statePath, *rate)
+		if err != nil {
+			return err
+		}
+		defer exporter.state.Close()
+		return exporter.export(ctx, &tree)
+	}
+}
+
+// newNotionExporter creates an exporter, loading the keys of bookmarks pushed by previous runs.
+func newNotionExporter(token, databaseID, statePath string, rate time.Duration) (*notionExporter, error) {
+	state, err := os.OpenFile(statePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening state file: %w", err)
+	}
+
+	// each line of the state file is the key of one pushed bookmark.
+	done := make(map[string]bool)
+	scanner := bufio.NewScanner(state)
+	for scanner.Scan() {
+		done[scanner.Text()] = true
+	}
+	if err := scanner.Err(); err != nil {
+		state.Close()
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	return &notionExporter{
+		token:      token,
+		databaseID: databaseID,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		limiter:    time.Tick(rate),
+		state:      state,
+		done:       done,
+	}, nil
+}
+
+// export pushes every bookmark in the tree that was not pushed by a previous run.
+func (e *notionExporter) export(ctx context.Context, root *Bookmark) error {
+	var err error
+	pushed, skipped := 0, 0
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if err != nil || b.isFolder() {
+			return
+		}
+
+		// skip bookmarks already recorded in the state file.
+		key := folderPath(path) + "\t" + b.URL
+		if e.done[key] {
+			skipped++
+			return
+		}
+
+		if err = e.push(ctx, b, folderPath(path)); err != nil {
+			err = fmt.Errorf("error pushing %q: %w", b.Title, err)
+			return
+		}
+		if _, err = fmt.Fprintln(e.state, key); err != nil {
+			err = fmt.Errorf("error writing state file: %w", err)
+			return
+		}
+		e.done[key] = true
+		pushed++
+	})
+	fmt.Printf("pushed %d bookmarks, skipped %d already pushed\n", pushed, skipped)
+	return err
+}
+
+// push creates a page for the bookmark, retrying with growing delays while the API
+// reports rate limiting, up to notionMaxRetries times.
+func (e *notionExporter) push(ctx context.Context, b *Bookmark, folder string) error {
+	properties := map[string]interface{}{
+		"Name":   map[string]interface{}{"title": notionText(b.Title)},
+		"URL":    map[string]interface{}{"url": b.URL},
+		"Folder": map[string]interface{}{"rich_text": notionText(folder)},
+	}
+	if b.AddAt != nil {
+		properties["Added"] = map[string]interface{}{"date": map[string]string{"start": b.AddAt.Format(time.RFC3339)}}
+	}
+	page := map[string]interface{}{
+		"parent":     map[string]string{"database_id": e.databaseID},
+		"properties": properties,
+	}
+	// enriched pages get their preview image as cover and their description as content.
+	if b.OGImage != "" {
+		page["cover"] = map[string]interface{}{"type": "external", "external": map[string]string{"url": b.OGImage}}
+	}
+	if b.MetaDescription != "" {
+		page["children"] = []map[string]interface{}{{
+			"object":    "block",
+			"type":      "paragraph",
+			"paragraph": map[string]interface{}{"rich_text": notionText(b.MetaDescription)},
+		}}
+	}
+	body, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+
+	for retries := 0; ; retries++ {
+		select {
+		case <-e.limiter:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, notionPagesURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+e.token)
+		req.Header.Set("Notion-Version", notionVersion)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := e.client.Do(req)
+		if err != nil {
+			return err
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			if retries == notionMaxRetries {
+				return fmt.Errorf("notion API still rate limiting after %d retries", retries)
+			}
+			// back off exponentially, or for longer when the API asks to.
+			wait := time.Second << retries
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(secs)*time.Second > wait {
+				wait = time.Duration(secs) * time.Second
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case resp.StatusCode >= 300:
+			return fmt.Errorf("notion API returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+		default:
+			return nil
+		}
+	}
+}
+
+// notionText builds a Notion rich text array holding a single plain text run.
+func notionText(content string) []map[string]interface{} {
+	return []map[string]interface{}{{"text": map[string]string{"content": content}}}
+}
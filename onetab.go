@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// isOneTab reports whether an export starts like OneTab's "Export URLs" text, which has
+// a "url | title" line per tab, possibly after the header of the first group.
+func isOneTab(head []byte) bool {
+	for _, line := range bytes.Split(bytes.TrimSpace(head), []byte("\n")) {
+		if _, header := oneTabHeader(string(line)); header {
+			continue
+		}
+		url, _, ok := bytes.Cut(bytes.TrimSpace(line), []byte(" | "))
+		return ok && bytes.Contains(url, []byte("://")) && !bytes.ContainsAny(url, " <")
+	}
+	return false
+}
+
+// oneTabTabCount matches the "N tabs" line OneTab shows above a group.
+var oneTabTabCount = regexp.MustCompile(`^\d+ tabs?$`)
+
+// oneTabTimeLayouts are the forms of the creation time OneTab shows above a group, which
+// follows the browser's locale.
+var oneTabTimeLayouts = []string{
+	"1/2/2006, 3:04:05 PM",
+	"1/2/2006, 15:04:05",
+	"2/1/2006, 15:04:05",
+	"2006-01-02 15:04:05",
+	"2006/1/2 15:04:05",
+}
+
+// oneTabHeader reports whether line is one of the lines OneTab shows above a group when
+// copied from its page rather than exported: "N tabs", or "Created <time>", whose time
+// it returns in the local time zone.
+func oneTabHeader(line string) (created *time.Time, ok bool) {
+	line = strings.TrimSpace(line)
+	if oneTabTabCount.MatchString(line) {
+		return nil, true
+	}
+	value, ok := strings.CutPrefix(line, "Created ")
+	if !ok {
+		return nil, false
+	}
+	for _, layout := range oneTabTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, true
+		}
+	}
+	return nil, false
+}
+
+// parseOneTab parses OneTab's "Export URLs" text into a root folder titled rootTitle with
+// a folder per tab group. Groups are separated by blank lines. The export itself does not
+// record when groups were saved, so their folders are numbered and left undated; groups
+// copied from OneTab's page with their "Created" header are dated and titled after it.
+func parseOneTab(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	root := Bookmark{Title: rootTitle}
+	var group *Bookmark
+	var created *time.Time // creation time of the group whose header was read last.
+
+	scanner := bufio.NewScanner(contextReader{ctx, r})
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			group = nil
+			continue
+		}
+		// a header starts the next group, even without a blank line before it.
+		if t, header := oneTabHeader(line); header {
+			group = nil
+			if t != nil {
+				created = t
+			}
+			continue
+		}
+		if group == nil {
+			folder := Bookmark{Title: fmt.Sprintf("OneTab group %d", len(root.Bookmarks)+1)}
+			if created != nil {
+				folder.Title = "OneTab " + created.Format("2006-01-02 15:04:05")
+				folder.AddAt = created
+				created = nil
+			}
+			root.Bookmarks = append(root.Bookmarks, folder)
+			group = &root.Bookmarks[len(root.Bookmarks)-1]
+		}
+
+		// tabs without a title are exported as the URL alone.
+		url, title, _ := strings.Cut(line, " | ")
+		if title == "" {
+			title = url
+		}
+		group.Bookmarks = append(group.Bookmarks, Bookmark{Title: title, URL: url})
+	}
+	if err := scanner.Err(); err != nil {
+		return Bookmark{}, fmt.Errorf("error reading OneTab export: %w", err)
+	}
+	return root, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import "time"
+
+// Option configures Parse.
+type Option func(*parseOptions)
+
+// parseOptions holds the settings changed by options.
+type parseOptions struct {
+	rootTitle    string
+	rootTitleSet bool // rootTitle was given with WithRootTitle, and wins over the H1 title.
+	lenient      bool
+	icons        bool
+	location     *time.Location
+	normalizers  []func(b *Bookmark)
+	bookmarklets bool
+}
+
+// WithRootTitle names the root folder synthesized for exports without a single top-level
+// folder. Without it, the export's H1 title names the root, or "Bookmarks" when there is
+// none.
+func WithRootTitle(title string) Option {
+	return func(o *parseOptions) {
+		o.rootTitle, o.rootTitleSet = title, true
+	}
+}
+
+// WithLenient makes Parse accept any input, as checked by FuzzParse in fuzz_test.go:
+// it fails only when reading r fails or ctx is done, never panics, and always returns a
+// tree that can be written as JSON. Timestamps that are not Unix times within the years
+// 0 to 9999 are dropped, documents without a bookmark list parse to an empty root, and
+// folders nested deeper than 256 levels are merged into their ancestor at that depth.
+// ParseResult reports each of these as a warning.
+func WithLenient() Option {
+	return func(o *parseOptions) {
+		o.lenient = true
+	}
+}
+
+// WithIcons keeps the icons browsers embed in exports as data URIs in the Icon field.
+// They are dropped by default, as they often make up most of an export's size.
+func WithIcons() Option {
+	return func(o *parseOptions) {
+		o.icons = true
+	}
+}
+
+// WithBookmarklets keeps the code of javascript: bookmarklets exactly as the document
+// holds it. By default their URLs are read as browsers read attributes, which turns the
+// line breaks of multi-line bookmarklets into newlines.
+func WithBookmarklets() Option {
+	return func(o *parseOptions) {
+		o.bookmarklets = true
+	}
+}
+
+// WithTimeLocation sets the location of parsed timestamps, which defaults to time.Local.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *parseOptions) {
+		o.location = loc
+	}
+}
+
+// WithNormalizer calls fn on every folder and bookmark of the parsed tree, root first.
+// Normalizers from several options run in the order given.
+func WithNormalizer(fn func(b *Bookmark)) Option {
+	return func(o *parseOptions) {
+		o.normalizers = append(o.normalizers, fn)
+	}
+}
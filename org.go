@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// orgTimestamp is the layout of an inactive org-mode timestamp.
+const orgTimestamp = "[2006-01-02 Mon 15:04]"
+
+// writeOrg writes the bookmark tree as an org-mode outline: folders become headings,
+// bookmarks become link headings, and timestamps go into property drawers.
+func writeOrg(w io.Writer, tree *Bookmark) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#+TITLE: %s\n", tree.Title)
+
+	var write func(b *Bookmark, level int)
+	write = func(b *Bookmark, level int) {
+		stars := strings.Repeat("*", level)
+		if b.isFolder() {
+			fmt.Fprintf(bw, "%s %s\n", stars, b.Title)
+		} else {
+			fmt.Fprintf(bw, "%s [[%s][%s]]\n", stars, orgLinkEscaper.Replace(b.URL), orgDescriptionEscaper.Replace(b.Title))
+		}
+		writeOrgProperties(bw, b.AddAt, b.UpdateAt)
+
+		for i := range b.Bookmarks {
+			write(&b.Bookmarks[i], level+1)
+		}
+	}
+	write(tree, 1)
+	return bw.Flush()
+}
+
+// writeOrgProperties writes a property drawer holding the given timestamps, if any are set.
+func writeOrgProperties(w io.Writer, addAt, updateAt *time.Time) {
+	if addAt == nil && updateAt == nil {
+		return
+	}
+	fmt.Fprintln(w, ":PROPERTIES:")
+	if addAt != nil {
+		fmt.Fprintf(w, ":ADDED:    %s\n", addAt.Format(orgTimestamp))
+	}
+	if updateAt != nil {
+		fmt.Fprintf(w, ":MODIFIED: %s\n", updateAt.Format(orgTimestamp))
+	}
+	fmt.Fprintln(w, ":END:")
+}
+
+// brackets would terminate an org link early, so they are percent-encoded in URLs
+// and swapped for parentheses in descriptions.
+var (
+	orgLinkEscaper        = strings.NewReplacer("[", "%5B", "]", "%5D")
+	orgDescriptionEscaper = strings.NewReplacer("[", "(", "]", ")")
+)
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:      "title",
+		usage:     "fill in missing titles from each page's <title>",
+		cacheable: true,
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			return func(client *http.Client) (Enricher, error) {
+				return &titleEnricher{client: client}, nil
+			}
+		},
+	})
+}
+
+// titleEnricher replaces empty titles, or titles that merely repeat the URL, with the
+// page's own title.
+type titleEnricher struct {
+	client *http.Client
+}
+
+func (e *titleEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	if strings.TrimSpace(b.Title) != "" && b.Title != b.URL {
+		return nil
+	}
+
+	doc, err := fetchPage(ctx, e.client, b.URL)
+	if err != nil {
+		return err
+	}
+	if title := strings.TrimSpace(doc.Find("title").First().Text()); title != "" {
+		b.Title = title
+	}
+	return nil
+}
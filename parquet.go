@@ -0,0 +1,23 @@
+package main
+
+import (
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// writeParquet writes the links of the tree as a flat Parquet table with path, title, url,
+// domain, add_at, update_at, tags, and description columns, ready to load into DuckDB or
+// Spark.
+func writeParquet(w io.Writer, tree *Bookmark) error {
+	return writeParquetRows(w, flattenBookmarks(tree))
+}
+
+// writeParquetRows writes the records of writeParquet.
+func writeParquetRows(w io.Writer, rows []flatBookmark) error {
+	writer := parquet.NewGenericWriter[flatBookmark](w, parquet.Compression(&parquet.Zstd))
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
@@ -1,147 +1,371 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
 )
 
-// bookmark represents a bookmark entry with its title, URL, parent, and sub-bookmarks.
+// bookmark represents a bookmark entry with its title, URL, and sub-bookmarks.
 type Bookmark struct {
-	Title     string     `json:"title"`
-	URL       string     `json:"url,omitempty"`
-	Parent    string     `json:"-"` // parent field is not included in JSON serialization.
-	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
-	AddAt     *time.Time `json:"addAt,omitempty"`
-	UpdateAt  *time.Time `json:"updateAt,omitempty"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url,omitempty"`
+	Bookmarks   []Bookmark `json:"bookmarks,omitempty"`
+	AddAt       *time.Time `json:"addAt,omitempty"`
+	UpdateAt    *time.Time `json:"updateAt,omitempty"`
+	Unsafe      bool       `json:"unsafe,omitempty"`  // URL uses a script-capable scheme.
+	Special     string     `json:"special,omitempty"` // canonical role of a browser's own folder (toolbar, menu, mobile, other).
+	Tags        []string   `json:"tags,omitempty"`
+	Description string     `json:"description,omitempty"` // notes saved with the link.
+	Private     bool       `json:"private,omitempty"`     // the link was not shared, as recorded by social bookmarking exports.
+
+	// attributes of HTML exports the parser does not interpret, such as FEEDURL or
+	// SHORTCUTURL, keyed by their lowercased names and written back by the html format.
+	Extra map[string]string `json:"extra,omitempty"`
+
+	// browsing history recorded by browsers that export it.
+	Visits    int        `json:"visits,omitempty"`
+	LastVisit *time.Time `json:"lastVisit,omitempty"`
+	Frecency  int        `json:"frecency,omitempty"` // Firefox's score combining how often and how recently a link was visited.
+
+	Source *Source `json:"source,omitempty"` // input a merged link came from.
+
+	// folder statistics, computed on request before writing.
+	Count       *int       `json:"count,omitempty"`     // links directly in the folder; zero is kept.
+	DeepCount   *int       `json:"deepCount,omitempty"` // links in the folder and its sub-folders; zero is kept.
+	NewestAddAt *time.Time `json:"newestAddAt,omitempty"`
+
+	// response metadata recorded by the link checker.
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	FinalURL    string `json:"finalUrl,omitempty"`
+
+	// fields added by enrichers.
+	Lang      string `json:"lang,omitempty"`      // ISO 639-1 code of the title's language.
+	Thumbnail string `json:"thumbnail,omitempty"` // path of the captured page screenshot.
+	Icon      string `json:"icon,omitempty"`      // site icon as a data URI.
+	Archive   string `json:"archive,omitempty"`   // closest Wayback Machine snapshot.
+
+	WordCount   int    `json:"wordCount,omitempty"`   // words in the page's article text.
+	ReadingTime int    `json:"readingTime,omitempty"` // estimated minutes to read the article.
+	Article     string `json:"article,omitempty"`     // path of the saved article text.
+
+	// the page's own description and its Open Graph preview.
+	MetaDescription string `json:"metaDescription,omitempty"`
+	OGTitle         string `json:"ogTitle,omitempty"`
+	OGImage         string `json:"ogImage,omitempty"`
+
+	OriginalURL string `json:"originalUrl,omitempty"` // URL before AMP and shortener links were resolved.
+}
+
+// Source records where a merged link came from, so merges can be audited and undone.
+type Source struct {
+	Name   string `json:"name"`   // label of the input, such as the browser and profile.
+	Path   string `json:"path"`   // file the input was read from.
+	Folder string `json:"folder"` // slash separated path of the folder holding the link in the input.
 }
 
-func main() {
-	// read the HTML file containing the bookmarks data.
-	htmlBytes, err := ioutil.ReadFile("bookmarks_test1.html")
+// defaultRootTitle names the root folder synthesized for exports without an H1 title.
+const defaultRootTitle = "Bookmarks"
+
+// Parse parses an exported bookmarks document and returns its bookmark tree. Without
+// options it is strict, failing on malformed timestamps and documents that hold no
+// bookmark list; see WithLenient.
+func Parse(ctx context.Context, r io.Reader, opts ...Option) (Bookmark, error) {
+	result, err := ParseResult(ctx, r, opts...)
+	return result.Tree, err
+}
+
+// ParseResult parses like Parse, and also returns the problems lenient parsing worked
+// around as warnings. Problems with an element, such as malformed timestamps, are
+// located in the document: the error of a strict parse and each warning is a *ParseError.
+func ParseResult(ctx context.Context, r io.Reader, opts ...Option) (Result, error) {
+	p := &parser{parseOptions: parseOptions{rootTitle: defaultRootTitle, location: time.Local}}
+	for _, opt := range opts {
+		opt(&p.parseOptions)
+	}
+
+	data, err := io.ReadAll(contextReader{ctx, r})
 	if err != nil {
-		fmt.Printf("error reading file: %s\n", err.Error())
-		return
+		return Result{}, fmt.Errorf("error reading HTML: %w", err)
 	}
+	p.data = data
 
 	// parse the HTML using goquery library.
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(htmlBytes)))
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
 	if err != nil {
-		fmt.Printf("error parsing HTML: %s\n", err.Error())
-		return
+		// the HTML parser refuses documents nested too deeply, which the tokenizer reads.
+		if p.lenient {
+			tree, err := p.parseDeep(ctx, data)
+			return Result{Tree: tree, Warnings: p.warnings}, err
+		}
+		return Result{}, fmt.Errorf("error parsing HTML: %w", err)
+	}
+	p.doc = doc
+	if doc.Find("DL").Length() == 0 {
+		if !p.lenient {
+			return Result{}, fmt.Errorf("error parsing HTML: no bookmark list found")
+		}
+		p.warn(fmt.Errorf("no bookmark list found"))
 	}
 
 	// extract bookmarks data from the HTML and create the bookmark tree.
-	bookmarks := parseBookmarks(doc)
-	tree := buildTree(bookmarks)
+	bookmarks := p.parseBookmarks(doc)
+	if p.err != nil {
+		return Result{}, p.err
+	}
+	return Result{Tree: p.finish(p.restoreBookmarklets(buildTree(bookmarks, p.rootTitleOf(data)), data)), Warnings: p.warnings}, nil
+}
 
-	// convert the bookmark tree to JSON and print the result.
-	jsonData, err := json.Marshal(tree)
-	if err != nil {
-		fmt.Printf("error converting to JSON: %s\n", err.Error())
-		return
+// restoreBookmarklets replaces the URLs of bookmarklets with their exact code in data
+// when WithBookmarklets is set.
+func (p *parser) restoreBookmarklets(tree Bookmark, data []byte) Bookmark {
+	if !p.bookmarklets {
+		return tree
 	}
-	fmt.Println(string(jsonData))
+	exact := rawBookmarklets(data)
+	walkBookmarks(&tree, func(b *Bookmark, path []string) {
+		if code, ok := exact[b.URL]; ok {
+			b.URL = code
+		}
+	})
+	return tree
 }
 
-// parseBookmarks extracts bookmarks from the goquery document and returns a slice of bookmark entries.
-func parseBookmarks(doc *goquery.Document) []Bookmark {
-	// initialize a map to store bookmarks with their titles as keys.
-	bookmarkMap := make(map[string]*Bookmark)
+// maxFolderDepth is how deeply folders read by parseDeep may nest.
+const maxFolderDepth = 256
 
-	// helper function to parse timestamp.
-	parseTime := func(timestamp string) *time.Time {
-		if len(timestamp) == 0 {
+// parseDeep builds the tree from the events of ParseStream, for documents nested more
+// deeply than the HTML parser allows. Entries of folders nested deeper than
+// maxFolderDepth go into their ancestor at that depth.
+func (p *parser) parseDeep(ctx context.Context, data []byte) (Bookmark, error) {
+	var root Bookmark
+	open := []*Bookmark{&root} // folders being read, innermost last.
+	depth := 0
+	tooDeep := false
+	var opts []Option
+	if p.icons {
+		opts = append(opts, WithIcons())
+	}
+	err := ParseStream(ctx, bytes.NewReader(data), func(e Event) error {
+		if e.Kind == EventWarning {
+			p.warnings = append(p.warnings, e.Warning)
 			return nil
 		}
-		ts, err := strconv.ParseInt(timestamp, 10, 64)
-		if err != nil {
-			fmt.Println("error parsing timestamp:", err.Error())
-			return nil
+		b := e.Bookmark
+		for _, t := range []*time.Time{b.AddAt, b.UpdateAt} {
+			if t != nil {
+				*t = t.In(p.location)
+			}
 		}
-		t := time.Unix(ts, 0)
-		return &t
-	}
 
-	// iterate over each H3 element in the document representing bookmark titles.
-	doc.Find("H3").Each(func(i int, header *goquery.Selection) {
-		// create a bookmark entry for the current H3 element.
-		bookmark := Bookmark{
-			Title:    header.Text(),
-			AddAt:    parseTime(header.AttrOr("add_date", "")),
-			UpdateAt: parseTime(header.AttrOr("last_modified", "")),
+		// a folder's entries are only appended to while it is innermost, so the
+		// pointers to open folders stay valid.
+		parent := open[len(open)-1]
+		switch e.Kind {
+		case EventFolderStart:
+			if depth++; depth <= maxFolderDepth {
+				parent.Bookmarks = append(parent.Bookmarks, b)
+				open = append(open, &parent.Bookmarks[len(parent.Bookmarks)-1])
+			} else if !tooDeep {
+				tooDeep = true
+				p.warn(fmt.Errorf("folders nested deeper than %d levels merged into their ancestor at that depth", maxFolderDepth))
+			}
+		case EventFolderEnd:
+			if depth--; depth < maxFolderDepth {
+				open = open[:len(open)-1]
+			}
+		case EventBookmark:
+			parent.Bookmarks = append(parent.Bookmarks, b)
 		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	return p.finish(p.restoreBookmarklets(buildTree(root.Bookmarks, p.rootTitleOf(data)), data)), nil
+}
 
-		// check if the header has a sibling DL element containing bookmarks.
-		if dlNode := header.Next(); dlNode.Is("DL") {
-			// iterate over each DT element representing sub-bookmark titles.
-			dlNode.ChildrenFiltered("DT").Each(func(j int, dtNode *goquery.Selection) {
-				if aNode := dtNode.Children().First(); aNode.Is("A") {
-					// create a bookmark entry for each bookmark within the DL element.
-					subBookmark := Bookmark{
-						Title:    aNode.Text(),
-						URL:      aNode.AttrOr("href", ""),
-						AddAt:    parseTime(aNode.AttrOr("add_date", "")),
-						UpdateAt: parseTime(aNode.AttrOr("last_modified", "")),
-					}
-					bookmark.Bookmarks = append(bookmark.Bookmarks, subBookmark)
+// rootTitleOf returns the title of a root folder synthesized for data: the one given with
+// WithRootTitle, or else the export's own H1 title, or else the default one.
+func (p *parser) rootTitleOf(data []byte) string {
+	if p.rootTitleSet {
+		return p.rootTitle
+	}
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for depth := 0; ; {
+		switch z.Next() {
+		case html.ErrorToken:
+			return p.rootTitle
+		case html.StartTagToken:
+			if name, _ := z.TagName(); string(name) == "h1" {
+				depth++
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "h1" && depth > 0 {
+				return p.rootTitle
+			}
+		case html.TextToken:
+			if depth > 0 {
+				if h1 := strings.TrimSpace(string(z.Text())); h1 != "" {
+					return h1
 				}
-			})
+			}
 		}
+	}
+}
 
-		// check if the bookmark has a parent folder (H3 element).
-		if parentDL := header.Parent().Parent(); parentDL.Is("DL") && parentDL.Prev().Is("H3") {
-			// set the parent field for the current bookmark.
-			bookmark.Parent = parentDL.Prev().Text()
-		}
+// finish runs the normalizers over the parsed tree.
+func (p *parser) finish(tree Bookmark) Bookmark {
+	for _, normalize := range p.normalizers {
+		walkBookmarks(&tree, func(b *Bookmark, path []string) {
+			normalize(b)
+		})
+	}
+	return tree
+}
 
-		// add the bookmark to the map.
-		bookmarkMap[bookmark.Title] = &bookmark
-	})
+// parseHTML parses an exported bookmarks document leniently, as the servers do. rootTitle
+// names the root folder when one has to be synthesized and the document has no H1 title.
+func parseHTML(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	fallback := func(o *parseOptions) { o.rootTitle = rootTitle }
+	return Parse(ctx, r, fallback, WithLenient())
+}
+
+// parser holds the state of one Parse call.
+type parser struct {
+	parseOptions
+	err      error      // first malformed attribute found when parsing strictly.
+	warnings []*Warning // problems worked around when parsing leniently.
+
+	// the document, and the offsets of its elements located by locate so far.
+	data      []byte
+	doc       *goquery.Document
+	positions map[*html.Node]int
+}
+
+// contextReader fails reads once its context is done, stopping parsers that consume the
+// input as they go.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-	// convert the map values to a slice and return.
-	bookmarks := make([]Bookmark, 0, len(bookmarkMap))
-	for _, bookmark := range bookmarkMap {
-		bookmarks = append(bookmarks, *bookmark)
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
 	}
-	return bookmarks
+	return r.r.Read(p)
 }
 
-// buildTree constructs the bookmark tree by finding the root folder and building the sub-trees.
-func buildTree(bookmarks []Bookmark) Bookmark {
-	// function to find the root folder by looking for a bookmark without a parent.
-	findRootFolder := func(bookmarks []Bookmark) *Bookmark {
-		for i := range bookmarks {
-			if bookmarks[i].Parent == "" {
-				return &bookmarks[i]
-			}
-		}
-		return nil
+// parseTimestamp converts a Unix timestamp attribute to a time, returning nil when it is
+// missing. Times outside the years 0 to 9999 cannot be written as RFC 3339 and are rejected.
+func parseTimestamp(timestamp string) (*time.Time, error) {
+	if len(timestamp) == 0 {
+		return nil, nil
 	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Unix(ts, 0)
+	if year := t.UTC().Year(); year < 0 || year > 9999 {
+		return nil, fmt.Errorf("timestamp %d out of range", ts)
+	}
+	return &t, nil
+}
 
-	root := findRootFolder(bookmarks)
-	if root == nil {
-		fmt.Println("root folder not found")
-		return Bookmark{}
+// time converts the timestamp attribute of node to a time in the configured location.
+// Malformed timestamps are dropped, and recorded as the parse error, or as a warning
+// when parsing leniently.
+func (p *parser) time(node *goquery.Selection, attr string) *time.Time {
+	t, err := parseTimestamp(node.AttrOr(attr, ""))
+	if err != nil {
+		p.problem(node, fmt.Errorf("error parsing timestamp: %w", err))
+		return nil
+	}
+	if t != nil {
+		*t = t.In(p.location)
 	}
+	return t
+}
+
+// parseBookmarks extracts the top-level entries of the goquery document, with each folder holding its own entries.
+func (p *parser) parseBookmarks(doc *goquery.Document) []Bookmark {
+	var bookmarks []Bookmark
+
+	// every DL element not nested in another one holds top-level entries.
+	doc.Find("DL").Each(func(i int, dl *goquery.Selection) {
+		if dl.ParentsFiltered("DL").Length() == 0 {
+			bookmarks = append(bookmarks, p.parseFolder(dl)...)
+		}
+	})
+	return bookmarks
+}
+
+// parseFolder returns the entries of a DL element, recursing into the DL of every sub-folder.
+// folders are matched to their entries by document structure alone, so any nesting depth
+// and repeated folder titles are handled.
+func (p *parser) parseFolder(dl *goquery.Selection) []Bookmark {
+	var bookmarks []Bookmark
 
-	// function to build the sub-tree recursively.
-	var buildSubTree func(parent *Bookmark)
-	buildSubTree = func(parent *Bookmark) {
-		for i := range bookmarks {
-			if bookmarks[i].Parent == parent.Title {
-				parent.Bookmarks = append(parent.Bookmarks, bookmarks[i])
-				buildSubTree(&parent.Bookmarks[len(parent.Bookmarks)-1])
+	// iterate over each DT element, which holds either a bookmark or a sub-folder.
+	dl.ChildrenFiltered("DT").Each(func(i int, dtNode *goquery.Selection) {
+		node := dtNode.ChildrenFiltered("A, H3").First()
+		switch {
+		case node.Is("A"):
+			// create a bookmark entry for the link.
+			bookmark := Bookmark{
+				Title:    node.Text(),
+				URL:      node.AttrOr("href", ""),
+				AddAt:    p.time(node, "add_date"),
+				UpdateAt: p.time(node, "last_modified"),
+				// Delicious exports record tags and privacy on the link.
+				Tags:    parseTags(node.AttrOr("tags", "")),
+				Private: node.AttrOr("private", "") == "1",
+				Extra:   extraAttrs(attrsOf(node), linkAttrs),
 			}
+			if p.icons {
+				bookmark.Icon = node.AttrOr("icon", "")
+			}
+			// notes follow the link in a DD element, which ends the DT.
+			if dd := dtNode.Next(); dd.Is("DD") {
+				bookmark.Description = strings.TrimSpace(dd.Text())
+			}
+			bookmarks = append(bookmarks, bookmark)
+		case node.Is("H3"):
+			// create a folder entry, reading its contents from the sibling DL element.
+			folder := Bookmark{
+				Title:    node.Text(),
+				AddAt:    p.time(node, "add_date"),
+				UpdateAt: p.time(node, "last_modified"),
+				Special: specialFromAttrs(func(name string) string {
+					return node.AttrOr(name, "")
+				}),
+				Extra: extraAttrs(attrsOf(node), folderAttrs),
+			}
+			if dlNode := node.NextFiltered("DL"); dlNode.Length() > 0 {
+				folder.Bookmarks = p.parseFolder(dlNode)
+			}
+			bookmarks = append(bookmarks, folder)
 		}
-	}
+	})
+	return bookmarks
+}
 
-	// build the sub-tree for the root folder.
-	buildSubTree(root)
-	return *root
+// buildTree returns the root folder. An export whose only top-level entry is a folder uses
+// that folder as the root; otherwise a root titled rootTitle holding every top-level entry
+// is synthesized.
+func buildTree(bookmarks []Bookmark, rootTitle string) Bookmark {
+	if len(bookmarks) == 1 && bookmarks[0].isFolder() {
+		return bookmarks[0]
+	}
+	return Bookmark{Title: rootTitle, Bookmarks: bookmarks}
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+// parseFixture parses one of the sample exports.
+func parseFixture(t *testing.T, path string) Bookmark {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tree, err := Parse(context.Background(), f)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+	return tree
+}
+
+// folderAt follows the folder titles from root, failing the test when one is missing.
+func folderAt(t *testing.T, root *Bookmark, titles ...string) *Bookmark {
+	t.Helper()
+	folder := root
+	for _, title := range titles {
+		var next *Bookmark
+		for i := range folder.Bookmarks {
+			if b := &folder.Bookmarks[i]; b.isFolder() && b.Title == title {
+				next = b
+				break
+			}
+		}
+		if next == nil {
+			t.Fatalf("no folder %q in %q", title, folder.Title)
+		}
+		folder = next
+	}
+	return folder
+}
+
+// titles returns the titles of the entries of folder, in order.
+func titles(folder *Bookmark) []string {
+	var titles []string
+	for _, b := range folder.Bookmarks {
+		titles = append(titles, b.Title)
+	}
+	return titles
+}
+
+func TestParseDeeplyNested(t *testing.T) {
+	tree := parseFixture(t, "bookmarks_test3.html")
+	if tree.Title != "Bookmarks bar" || tree.Special != specialToolbar {
+		t.Fatalf("root is %q (%q), want the bookmarks bar", tree.Title, tree.Special)
+	}
+
+	depth := 0
+	walkBookmarks(&tree, func(b *Bookmark, path []string) {
+		if len(path) > depth {
+			depth = len(path)
+		}
+	})
+	if depth != 8 {
+		t.Errorf("deepest entry is %d folders down, want 8", depth)
+	}
+
+	tests := []struct {
+		path []string
+		want []string
+	}{
+		{nil, []string{"Go", "Dev", "Example"}},
+		{[]string{"Dev"}, []string{"Docs", "Empty", "GitHub"}},
+		{[]string{"Dev", "Docs"}, []string{"Go packages", "Languages"}},
+		{[]string{"Dev", "Docs", "Languages"}, []string{"Systems", "Python"}},
+		{[]string{"Dev", "Docs", "Languages", "Systems", "Docs"}, []string{"Rust", "Zig"}},
+		{[]string{"Dev", "Docs", "Languages", "Systems", "Docs", "Rust"}, []string{"The Rust Book", "Docs"}},
+		{[]string{"Dev", "Docs", "Languages", "Systems", "Docs", "Rust", "Docs"}, []string{"std"}},
+		{[]string{"Dev", "Empty"}, nil},
+	}
+	for _, test := range tests {
+		got := titles(folderAt(t, &tree, test.path...))
+		if len(got) != len(test.want) {
+			t.Errorf("%v holds %q, want %q", test.path, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%v holds %q, want %q", test.path, got, test.want)
+				break
+			}
+		}
+	}
+
+	std := folderAt(t, &tree, "Dev", "Docs", "Languages", "Systems", "Docs", "Rust", "Docs").Bookmarks[0]
+	if std.URL != "https://doc.rust-lang.org/std/" || std.AddAt == nil || std.AddAt.Unix() != 1689239623 {
+		t.Errorf("deepest link is %+v", std)
+	}
+
+	// nesting beyond what the HTML parser accepts is read from the token stream, which
+	// reports malformed timestamps and keeps icons like the tree parser.
+	const folders = 600
+	var deep strings.Builder
+	deep.WriteString("<DL><p>\n")
+	for i := 0; i < folders; i++ {
+		deep.WriteString("<DT><H3>Deep</H3>\n<DL><p>\n")
+	}
+	deep.WriteString(`<DT><A HREF="https://example.com/" ADD_DATE="soon" ICON="data:image/png;base64,AA==">Bottom</A>` + "\n")
+	for i := 0; i <= folders; i++ {
+		deep.WriteString("</DL><p>\n")
+	}
+	result, err := ParseResult(context.Background(), strings.NewReader(deep.String()), WithLenient(), WithIcons())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warning *Warning
+	for _, w := range result.Warnings {
+		if strings.Contains(w.Err.Error(), "error parsing timestamp") {
+			warning = w
+		}
+	}
+	if warning == nil {
+		t.Fatalf("no warning about the malformed timestamp in %v", result.Warnings)
+	}
+	if line := 2*folders + 2; warning.Line != line || warning.Column != 5 {
+		t.Errorf("timestamp warning at line %d, column %d, want line %d, column 5", warning.Line, warning.Column, line)
+	}
+	var bottom *Bookmark
+	walkBookmarks(&result.Tree, func(b *Bookmark, path []string) {
+		if b.Title == "Bottom" {
+			bottom = b
+		}
+	})
+	if bottom == nil || bottom.AddAt != nil || bottom.Icon != "data:image/png;base64,AA==" {
+		t.Errorf("deepest link is %+v", bottom)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ParseError describes a problem with an export, located at the element causing it when
+// there is one. Elements the HTML parser copies while repairing broken documents are
+// located at the tag they were copied from.
+type ParseError struct {
+	Line    int    // 1-based line of the element's start tag, or 0 for the whole document.
+	Column  int    // 1-based column of the start tag, counted in characters.
+	Offset  int    // byte offset of the start tag.
+	Snippet string // the start tag as written, shortened to maxSnippetLen characters.
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d, column %d: %s (near %s)", e.Line, e.Column, e.Err, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Warning is a problem lenient parsing worked around, such as a malformed timestamp
+// that was dropped.
+type Warning = ParseError
+
+// Result is the outcome of ParseResult: the bookmark tree and, when parsing leniently,
+// the problems found on the way.
+type Result struct {
+	Tree     Bookmark
+	Warnings []*Warning
+}
+
+// maxSnippetLen bounds the length of ParseError snippets, as bookmarklets and icons can
+// make start tags very long.
+const maxSnippetLen = 80
+
+// offsetAttr is the attribute markTags gives start tags to carry their offset.
+const offsetAttr = "data-parse-bookmarks-offset"
+
+// markTags returns data with every A and H3 start tag given an offsetAttr attribute
+// holding the tag's byte offset in data. Parsing the result yields the same tree as
+// parsing data, with each element carrying the offset of the tag it was built from.
+func markTags(data []byte) []byte {
+	var marked bytes.Buffer
+	marked.Grow(len(data) + len(data)/8)
+	z := html.NewTokenizer(bytes.NewReader(data))
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			marked.Write(data[offset:])
+			return marked.Bytes()
+		}
+		// Raw is read first, as reading the tag name may modify it.
+		start := offset
+		offset += len(z.Raw())
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			if name, _ := z.TagName(); string(name) == "a" || string(name) == "h3" {
+				end := start + 1 + len(name)
+				marked.Write(data[start:end])
+				fmt.Fprintf(&marked, " %s=\"%d\"", offsetAttr, start)
+				marked.Write(data[end:offset])
+				continue
+			}
+		}
+		marked.Write(data[start:offset])
+	}
+}
+
+// locate returns err as a ParseError located at the start tag of node. The first call
+// parses the document again with its tags marked by markTags, and takes the offset of
+// each element from its counterpart in that identically shaped tree.
+func (p *parser) locate(node *goquery.Selection, err error) *ParseError {
+	if p.positions == nil {
+		p.positions = make(map[*html.Node]int)
+		if marked, err := html.Parse(bytes.NewReader(markTags(p.data))); err == nil {
+			var walk func(n, m *html.Node)
+			walk = func(n, m *html.Node) {
+				for _, attr := range m.Attr {
+					if attr.Key == offsetAttr {
+						if offset, err := strconv.Atoi(attr.Val); err == nil {
+							p.positions[n] = offset
+						}
+					}
+				}
+				for c, d := n.FirstChild, m.FirstChild; c != nil && d != nil; c, d = c.NextSibling, d.NextSibling {
+					walk(c, d)
+				}
+			}
+			walk(p.doc.Get(0), marked)
+		}
+	}
+	offset, ok := p.positions[node.Get(0)]
+	if !ok {
+		return &ParseError{Err: err}
+	}
+	before := p.data[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return &ParseError{
+		Line:    bytes.Count(before, []byte("\n")) + 1,
+		Column:  utf8.RuneCount(before[lineStart:]) + 1,
+		Offset:  offset,
+		Snippet: snippet(p.data[offset:]),
+		Err:     err,
+	}
+}
+
+// snippet returns the start tag at the beginning of data, shortened to maxSnippetLen
+// characters.
+func snippet(data []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	z.Next()
+	tag := strings.Join(strings.Fields(string(z.Raw())), " ")
+	if utf8.RuneCountInString(tag) > maxSnippetLen {
+		tag = string([]rune(tag)[:maxSnippetLen-1]) + "…"
+	}
+	return tag
+}
+
+// problem records err, found at node, as the parse error when parsing strictly or as a
+// warning otherwise. Only the first error is kept.
+func (p *parser) problem(node *goquery.Selection, err error) {
+	switch {
+	case p.lenient:
+		p.warnings = append(p.warnings, p.locate(node, err))
+	case p.err == nil:
+		p.err = p.locate(node, err)
+	}
+}
+
+// warn records a warning about the document as a whole.
+func (p *parser) warn(err error) {
+	p.warnings = append(p.warnings, &Warning{Err: err})
+}
+
+// printWarnings reports the warnings of a parse on stderr, one per line.
+func printWarnings(warnings []*Warning) {
+	for _, warning := range warnings {
+		warnf("%s", warning)
+	}
+}
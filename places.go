@@ -0,0 +1,181 @@
+//go:build !js
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	importers = append(importers, importer{name: "places", detect: isPlaces, parse: parsePlaces})
+}
+
+// Firefox's bookmark types in moz_bookmarks.
+const (
+	placesBookmark = 1
+	placesFolder   = 2
+)
+
+// placesRoots maps the GUIDs of the folders Firefox creates itself to their roles and
+// the titles Firefox displays for them.
+var placesRoots = map[string]struct{ special, title string }{
+	"menu________": {specialMenu, "Bookmarks Menu"},
+	"toolbar_____": {specialToolbar, "Bookmarks Toolbar"},
+	"unfiled_____": {specialOther, "Other Bookmarks"},
+	"mobile______": {specialMobile, "Mobile Bookmarks"},
+}
+
+// placesTagsRoot is the GUID of the folder holding Firefox's tags, a folder per tag with
+// a bookmark for every tagged URL.
+const placesTagsRoot = "tags________"
+
+// isPlaces reports whether head starts an SQLite database, as Firefox's places.sqlite is.
+func isPlaces(head []byte) bool {
+	return bytes.HasPrefix(head, []byte("SQLite format 3\x00"))
+}
+
+// parsePlaces reads the bookmarks of a Firefox profile's places.sqlite, with the visit
+// count, last visit, and frecency of every link taken from its history. Tags become the
+// Tags of the links they were given to. Close Firefox or copy the file first, as changes
+// Firefox has not yet checkpointed from its write-ahead log are not read.
+func parsePlaces(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	// SQLite reads from files, so the database is copied to a temporary one.
+	tmp, err := os.CreateTemp("", "places-*.sqlite")
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("error reading places database: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, contextReader{ctx, r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("error reading places database: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+tmp.Name()+"?mode=ro")
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("error opening places database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT b.id, b.type, b.parent, b.title, b.dateAdded, b.lastModified, b.guid,
+			IFNULL(p.url, ''), IFNULL(p.frecency, 0),
+			(SELECT COUNT(*) FROM moz_historyvisits v WHERE v.place_id = p.id),
+			(SELECT MAX(v.visit_date) FROM moz_historyvisits v WHERE v.place_id = p.id)
+		FROM moz_bookmarks b LEFT JOIN moz_places p ON p.id = b.fk
+		ORDER BY b.parent, b.position`)
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("error reading places database: %w", err)
+	}
+	defer rows.Close()
+
+	// read every entry, remembering each folder's children in order.
+	type entry struct {
+		Bookmark
+		guid string
+	}
+	entries := make(map[int64]*entry)
+	children := make(map[int64][]int64)
+	var rootID int64 = -1
+	for rows.Next() {
+		var id, parent, added, modified int64
+		var kind int
+		var title, guid sql.NullString
+		var lastVisit sql.NullInt64
+		e := &entry{}
+		if err := rows.Scan(&id, &kind, &parent, &title, &added, &modified, &guid, &e.URL, &e.Frecency, &e.Visits, &lastVisit); err != nil {
+			return Bookmark{}, fmt.Errorf("error reading places database: %w", err)
+		}
+		if kind != placesBookmark && kind != placesFolder {
+			continue
+		}
+		e.Title, e.guid = title.String, guid.String
+		e.AddAt, e.UpdateAt = placesTime(added), placesTime(modified)
+		if lastVisit.Valid {
+			e.LastVisit = placesTime(lastVisit.Int64)
+		}
+		if kind == placesFolder {
+			e.URL, e.Frecency, e.Visits = "", 0, 0
+		}
+		if parent == id || parent == 0 {
+			rootID = id
+		}
+		entries[id] = e
+		children[parent] = append(children[parent], id)
+	}
+	if err := rows.Err(); err != nil {
+		return Bookmark{}, fmt.Errorf("error reading places database: %w", err)
+	}
+	if rootID < 0 {
+		return Bookmark{}, fmt.Errorf("error reading places database: no root folder")
+	}
+
+	// collect the tags given to every URL.
+	tags := make(map[string][]string)
+	for _, id := range children[rootID] {
+		if entries[id].guid != placesTagsRoot {
+			continue
+		}
+		for _, tagID := range children[id] {
+			for _, linkID := range children[tagID] {
+				link := entries[linkID]
+				if link != nil && !link.isFolder() {
+					tags[link.URL] = append(tags[link.URL], entries[tagID].Title)
+				}
+			}
+		}
+	}
+
+	var build func(id int64) Bookmark
+	build = func(id int64) Bookmark {
+		e := entries[id]
+		b := e.Bookmark
+		if b.isFolder() {
+			for _, child := range children[id] {
+				if entries[child] != nil && child != id {
+					b.Bookmarks = append(b.Bookmarks, build(child))
+				}
+			}
+		} else {
+			b.Tags = tags[b.URL]
+		}
+		return b
+	}
+	var top []Bookmark
+	for _, id := range children[rootID] {
+		e := entries[id]
+		if id == rootID || e == nil || e.guid == placesTagsRoot {
+			continue
+		}
+		folder := build(id)
+		if root, ok := placesRoots[e.guid]; ok {
+			folder.Special, folder.Title = root.special, root.title
+		}
+		top = append(top, folder)
+	}
+	return buildTree(top, rootTitle), nil
+}
+
+// placesTime converts a places timestamp, microseconds since the Unix epoch, to a time,
+// returning nil for missing or out of range timestamps.
+func placesTime(us int64) *time.Time {
+	if us <= 0 {
+		return nil
+	}
+	t, err := parseTimestamp(strconv.FormatInt(us/1e6, 10))
+	if err != nil || t == nil {
+		return nil
+	}
+	*t = t.Add(time.Duration(us%1e6) * time.Microsecond)
+	return t
+}
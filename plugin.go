@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// outputPluginPrefix names the executables providing extra output formats: -format foo
+// runs parse-bookmarks-format-foo from PATH with the tree as JSON on stdin.
+const outputPluginPrefix = "parse-bookmarks-format-"
+
+// outputPlugin returns a writer for the format backed by an external executable.
+func outputPlugin(format string) (func(w io.Writer, tree *Bookmark) error, bool) {
+	path, err := exec.LookPath(outputPluginPrefix + format)
+	if err != nil {
+		return nil, false
+	}
+	return func(w io.Writer, tree *Bookmark) error {
+		var input bytes.Buffer
+		if err := writeJSON(&input, tree); err != nil {
+			return err
+		}
+		cmd := exec.Command(path)
+		cmd.Stdin = &input
+		cmd.Stdout = w
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("output plugin %s failed: %w", format, err)
+		}
+		return nil
+	}, true
+}
+
+// execPerBookmark pipes each bookmark as JSON to a shell command, with the folder path in
+// $BOOKMARK_FOLDER. A command exiting with status 1 drops the bookmark, and JSON written
+// to stdout replaces it; empty output keeps the bookmark unchanged.
+func execPerBookmark(ctx context.Context, root *Bookmark, command string) error {
+	var err error
+	var run func(folder *Bookmark, path []string)
+	run = func(folder *Bookmark, path []string) {
+		path = append(path, folder.Title)
+		kept := folder.Bookmarks[:0]
+		for _, b := range folder.Bookmarks {
+			if err != nil {
+				kept = append(kept, b)
+				continue
+			}
+			if b.isFolder() {
+				run(&b, path)
+				kept = append(kept, b)
+				continue
+			}
+
+			input, marshalErr := json.Marshal(b)
+			if marshalErr != nil {
+				err = marshalErr
+				return
+			}
+			cmd := exec.CommandContext(ctx, "sh", "-c", command)
+			cmd.Stdin = bytes.NewReader(input)
+			cmd.Stderr = os.Stderr
+			cmd.Env = append(os.Environ(), "BOOKMARK_FOLDER="+folderPath(path))
+			output, runErr := cmd.Output()
+
+			// exit status 1 filters the bookmark out; other failures abort.
+			var exitErr *exec.ExitError
+			if errors.As(runErr, &exitErr) && exitErr.ExitCode() == 1 {
+				continue
+			}
+			if runErr != nil {
+				err = fmt.Errorf("command failed for %q: %w", b.URL, runErr)
+				return
+			}
+			if len(strings.TrimSpace(string(output))) > 0 {
+				var replacement Bookmark
+				if err = json.Unmarshal(output, &replacement); err != nil {
+					err = fmt.Errorf("command returned invalid JSON for %q: %w", b.URL, err)
+					return
+				}
+				b = replacement
+			}
+			kept = append(kept, b)
+		}
+		folder.Bookmarks = kept
+	}
+	run(root, nil)
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/onntztzf/parse-bookmarks/bookmarkspb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// writeProtobuf writes the bookmark tree as a binary parsebookmarks.v1.Bookmark message,
+// as defined in bookmarkspb/bookmark.proto.
+func writeProtobuf(w io.Writer, tree *Bookmark) error {
+	data, err := proto.Marshal(toProto(tree))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeProtobufJSON writes the bookmark tree in the canonical JSON mapping of the
+// parsebookmarks.v1.Bookmark message.
+func writeProtobufJSON(w io.Writer, tree *Bookmark) error {
+	data, err := protojson.Marshal(toProto(tree))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// toProto converts a bookmark and its entries to their protocol buffer form.
+func toProto(b *Bookmark) *bookmarkspb.Bookmark {
+	timestamp := func(t *time.Time) *timestamppb.Timestamp {
+		if t == nil {
+			return nil
+		}
+		return timestamppb.New(*t)
+	}
+	int32Ptr := func(n *int) *int32 {
+		if n == nil {
+			return nil
+		}
+		return proto.Int32(int32(*n))
+	}
+
+	pb := &bookmarkspb.Bookmark{
+		Title:       b.Title,
+		Url:         b.URL,
+		AddAt:       timestamp(b.AddAt),
+		UpdateAt:    timestamp(b.UpdateAt),
+		Unsafe:      b.Unsafe,
+		Special:     b.Special,
+		Tags:        b.Tags,
+		Private:     b.Private,
+		Description: b.Description,
+		Extra:       b.Extra,
+		Visits:      int32(b.Visits),
+		LastVisit:   timestamp(b.LastVisit),
+		Frecency:    int32(b.Frecency),
+		Count:       int32Ptr(b.Count),
+		DeepCount:   int32Ptr(b.DeepCount),
+		NewestAddAt: timestamp(b.NewestAddAt),
+		Status:      int32(b.Status),
+		ContentType: b.ContentType,
+		FinalUrl:    b.FinalURL,
+		Lang:        b.Lang,
+		Thumbnail:   b.Thumbnail,
+		Icon:        b.Icon,
+		Archive:     b.Archive,
+		WordCount:   int32(b.WordCount),
+		ReadingTime: int32(b.ReadingTime),
+		Article:     b.Article,
+		OriginalUrl: b.OriginalURL,
+
+		MetaDescription: b.MetaDescription,
+		OgTitle:         b.OGTitle,
+		OgImage:         b.OGImage,
+	}
+	if b.Source != nil {
+		pb.Source = &bookmarkspb.Source{Name: b.Source.Name, Path: b.Source.Path, Folder: b.Source.Folder}
+	}
+	for i := range b.Bookmarks {
+		pb.Bookmarks = append(pb.Bookmarks, toProto(&b.Bookmarks[i]))
+	}
+	return pb
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:  "reading-time",
+		usage: "count the words of each page's article text and estimate the minutes needed to read it",
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			wpm := fs.Int("reading-wpm", 230, "reading speed, in words per minute, of -reading-time estimates")
+			dir := fs.String("article-dir", "", "directory to save the article text of each page in, for -reading-time")
+			return func(client *http.Client) (Enricher, error) {
+				if *wpm <= 0 {
+					return nil, fmt.Errorf("-reading-wpm must be positive")
+				}
+				if *dir != "" {
+					if err := os.MkdirAll(*dir, 0755); err != nil {
+						return nil, fmt.Errorf("error creating article directory: %w", err)
+					}
+				}
+				return &readingTimeEnricher{client: client, wpm: *wpm, dir: *dir}, nil
+			}
+		},
+	})
+}
+
+// readingTimeEnricher records the word count and reading time of each page's article,
+// optionally saving the article text to a directory.
+type readingTimeEnricher struct {
+	client *http.Client
+	wpm    int
+	dir    string
+}
+
+func (e *readingTimeEnricher) Enrich(ctx context.Context, b *Bookmark) error {
+	doc, err := fetchPage(ctx, e.client, b.URL)
+	if err != nil {
+		return err
+	}
+	text := articleText(doc)
+	b.WordCount = countWords(text)
+	b.ReadingTime = (b.WordCount + e.wpm - 1) / e.wpm
+	if e.dir == "" || text == "" {
+		return nil
+	}
+
+	sum := sha1.Sum([]byte(b.URL))
+	path := filepath.Join(e.dir, hex.EncodeToString(sum[:])+".txt")
+	if err := os.WriteFile(path, []byte(text+"\n"), 0644); err != nil {
+		return err
+	}
+	b.Article = path
+	return nil
+}
+
+// articleText extracts the main text of a page, preferring its article or main element
+// over the whole body and leaving out navigation, scripts, and other chrome. Paragraphs
+// are separated by blank lines.
+func articleText(doc *goquery.Document) string {
+	// the document may be shared with other enrichers, so a copy is trimmed.
+	root := doc.Find("article").First()
+	if root.Length() == 0 {
+		root = doc.Find("main, [role=main]").First()
+	}
+	if root.Length() == 0 {
+		root = doc.Find("body")
+	}
+	root = root.Clone()
+	root.Find("script, style, noscript, nav, header, footer, aside, form, iframe, svg").Remove()
+
+	var paragraphs []string
+	blocks := root.Find("p, h1, h2, h3, h4, h5, h6, li, blockquote, pre")
+	if blocks.Length() == 0 {
+		blocks = root
+	}
+	blocks.Each(func(i int, block *goquery.Selection) {
+		// blocks nested in other blocks are part of their text already.
+		if block.ParentsFiltered("p, li, blockquote, pre").Length() > 0 {
+			return
+		}
+		if text := strings.Join(strings.Fields(block.Text()), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	})
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// countWords counts the words of text. Chinese, Japanese, and Korean characters are
+// counted as a word each, as those scripts do not separate words with spaces.
+func countWords(text string) int {
+	words := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			words++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		case r == '\'' || r == '’' || r == '-':
+			// apostrophes and hyphens join the parts of a word.
+		default:
+			inWord = false
+		}
+	}
+	return words
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// fetchTimeout bounds the download of an input file given as a URL.
+const fetchTimeout = 5 * time.Minute
+
+// isRemoteInput reports whether an input argument names a file to download rather than a
+// local path.
+func isRemoteInput(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// parseHeaders converts "Name: value" flag values to request headers.
+func parseHeaders(values []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("malformed header %q, expected \"Name: value\"", value)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	}
+	return header, nil
+}
+
+// fetchClient downloads input files. Redirects to another host drop every request header,
+// so credentials meant for the host named on the command line are not sent elsewhere.
+var fetchClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		if req.URL.Host != via[0].URL.Host {
+			req.Header = make(http.Header)
+		}
+		return nil
+	},
+}
+
+// fetchInput downloads an input file, sending header with the request.
+func fetchInput(ctx context.Context, url string, header http.Header) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching input: %w", err)
+	}
+	req.Header = header.Clone()
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching input: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching input: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching input: %w", err)
+	}
+	return data, nil
+}
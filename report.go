@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// runReport summarizes a run for automation checking on scheduled conversions. It is
+// collected when a subcommand is given -report, and written as JSON once the subcommand
+// returns, or after every run of subcommands repeating on a schedule. Its methods do
+// nothing on a nil report, so call sites need not check whether one is being collected.
+type runReport struct {
+	Command  string         `json:"command"`
+	Args     []string       `json:"args"`
+	Started  time.Time      `json:"started"`
+	Duration float64        `json:"durationSeconds"`
+	Error    string         `json:"error,omitempty"`
+	Inputs   []reportTree   `json:"inputs"`
+	Outputs  []reportTree   `json:"outputs"`
+	Counts   map[string]int `json:"counts"` // changed entries by kind, such as dropped, deduped, or rewritten.
+	Warnings []string       `json:"warnings"`
+
+	path      string
+	scheduled bool       // set once runEnded wrote the report of a scheduled run.
+	mu        sync.Mutex // enrichers report concurrently.
+}
+
+// reportTree describes a tree read or written by the run.
+type reportTree struct {
+	Path    string `json:"path"` // "-" for stdout.
+	Links   int    `json:"links"`
+	Folders int    `json:"folders"`
+	Error   string `json:"error,omitempty"`
+}
+
+// currentReport is the report of the running subcommand, or nil without -report.
+var currentReport *runReport
+
+// startReport begins collecting the report of a subcommand, to be written to path.
+func startReport(command string, args []string, path string) {
+	currentReport = &runReport{Command: command, Args: args, path: path}
+	currentReport.reset()
+}
+
+// reset empties the report for a run starting now.
+func (r *runReport) reset() {
+	r.Started = time.Now()
+	r.Duration = 0
+	r.Error = ""
+	r.Inputs = []reportTree{}
+	r.Outputs = []reportTree{}
+	r.Counts = make(map[string]int)
+	r.Warnings = []string{}
+}
+
+// newReportTree counts the links and folders of tree.
+func newReportTree(path string, tree *Bookmark) reportTree {
+	t := reportTree{Path: path}
+	walkBookmarks(tree, func(b *Bookmark, _ []string) {
+		if b.isFolder() {
+			t.Folders++
+		} else {
+			t.Links++
+		}
+	})
+	return t
+}
+
+// countLinks returns the number of links in tree.
+func countLinks(tree *Bookmark) int {
+	return newReportTree("", tree).Links
+}
+
+// input records an input read by the run, or the error reading it.
+func (r *runReport) input(path string, tree *Bookmark, err error) {
+	if r == nil {
+		return
+	}
+	t := reportTree{Path: path}
+	if err != nil {
+		t.Error = err.Error()
+	} else {
+		t = newReportTree(path, tree)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Inputs = append(r.Inputs, t)
+}
+
+// output records a tree written by the run to path, or to stdout when path is empty.
+func (r *runReport) output(path string, tree *Bookmark) {
+	if r == nil {
+		return
+	}
+	if path == "" {
+		path = "-"
+	}
+	t := newReportTree(path, tree)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Outputs = append(r.Outputs, t)
+}
+
+// count adds n to the number of entries changed in the named way.
+func (r *runReport) count(kind string, n int) {
+	if r == nil || n == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Counts[kind] += n
+}
+
+// warnf prints a warning on stderr, prefixed with "warning: ", and records it in the
+// report.
+func warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Fprintln(os.Stderr, "warning: "+message)
+	if r := currentReport; r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.Warnings = append(r.Warnings, message)
+	}
+}
+
+// runEnded writes the report of one run of a subcommand repeating on a schedule, such as
+// sync -every, and starts the report of the next run. The file thus describes the latest
+// finished run, and is left alone when the subcommand exits without an error.
+func (r *runReport) runEnded(err error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.write(err); err != nil {
+		return err
+	}
+	r.reset()
+	r.scheduled = true
+	return nil
+}
+
+// finish writes the report, recording the error the subcommand returned, if any.
+func (r *runReport) finish(err error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.scheduled && err == nil {
+		return nil
+	}
+	return r.write(err)
+}
+
+// write writes the report, recording err as the outcome of the run. The caller holds mu.
+func (r *runReport) write(err error) error {
+	r.Duration = time.Since(r.Started).Seconds()
+	if err != nil {
+		r.Error = err.Error()
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// rule files a link into a folder, tags it, or both, when it matches every condition the
+// rule sets.
+type rule struct {
+	Domain string   `json:"domain"` // host, matching its subdomains too.
+	URL    string   `json:"url"`    // regular expression matched against the URL.
+	Title  []string `json:"title"`  // keywords, any of which the title contains as a word.
+	Folder string   `json:"folder"` // slash separated path below the root.
+	Tags   []string `json:"tags"`
+
+	url *regexp.Regexp
+}
+
+// loadRules reads a rules file, a JSON array of rules.
+func loadRules(path string) ([]rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules: %w", err)
+	}
+	var rules []rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing rules: %w", err)
+	}
+	for i := range rules {
+		r := &rules[i]
+		if r.Domain == "" && r.URL == "" && len(r.Title) == 0 {
+			return nil, fmt.Errorf("error parsing rules: rule %d has no conditions", i+1)
+		}
+		if r.Folder == "" && len(r.Tags) == 0 {
+			return nil, fmt.Errorf("error parsing rules: rule %d has neither a folder nor tags", i+1)
+		}
+		if r.URL != "" {
+			if r.url, err = regexp.Compile(r.URL); err != nil {
+				return nil, fmt.Errorf("error parsing rules: rule %d: %w", i+1, err)
+			}
+		}
+		r.Domain = strings.ToLower(strings.TrimPrefix(r.Domain, "."))
+	}
+	return rules, nil
+}
+
+// matches reports whether the link meets every condition of the rule.
+func (r *rule) matches(b *Bookmark) bool {
+	if r.Domain != "" {
+		u, err := url.Parse(b.URL)
+		if err != nil {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		if host != r.Domain && !strings.HasSuffix(host, "."+r.Domain) {
+			return false
+		}
+	}
+	if r.url != nil && !r.url.MatchString(b.URL) {
+		return false
+	}
+	if len(r.Title) > 0 {
+		words := titleWords(b.Title)
+		found := false
+		for _, keyword := range r.Title {
+			found = found || containsString(words, strings.ToLower(keyword))
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// applyRules tags every link with the tags of all the rules it matches and moves it to
+// the folder of the first matching rule that names one, creating folders as needed.
+// Links already in that folder stay where they are. It returns the number of links moved.
+func applyRules(root *Bookmark, rules []rule) int {
+	targets := make(map[*Bookmark][]string)
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() {
+			return
+		}
+		folder := ""
+		for i := range rules {
+			r := &rules[i]
+			if !r.matches(b) {
+				continue
+			}
+			for _, tag := range r.Tags {
+				if !containsString(b.Tags, tag) {
+					b.Tags = append(b.Tags, tag)
+				}
+			}
+			if folder == "" {
+				folder = strings.Trim(r.Folder, "/")
+			}
+		}
+		if folder != "" && folder != folderPath(path[1:]) {
+			targets[b] = strings.Split(folder, "/")
+		}
+	})
+	relocate(root, targets)
+	return len(targets)
+}
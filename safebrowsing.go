@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const safeBrowsingSearchURL = "https://safebrowsing.googleapis.com/v5/hashes:search"
+
+// safeBrowsing looks URLs up in the Google Safe Browsing API. Only 4-byte prefixes of
+// the URL hashes are sent, so the service never learns which bookmarks are checked.
+type safeBrowsing struct {
+	key    string
+	client *http.Client
+	cache  map[string]map[string]string // hash prefix -> full hash -> threat type.
+}
+
+func newSafeBrowsing(key string) *safeBrowsing {
+	return &safeBrowsing{
+		key:    key,
+		client: &http.Client{Timeout: 30 * time.Second},
+		cache:  make(map[string]map[string]string),
+	}
+}
+
+// check reports the threat type of the first URL expression known to be unsafe.
+func (s *safeBrowsing) check(ctx context.Context, b *Bookmark) (string, error) {
+	hashes := safeBrowsingHashes(b.URL)
+
+	// look up the prefixes not answered by an earlier request.
+	var missing []string
+	for _, hash := range hashes {
+		if _, ok := s.cache[hash[:4]]; !ok {
+			missing = append(missing, hash[:4])
+		}
+	}
+	if len(missing) > 0 {
+		if err := s.search(ctx, missing); err != nil {
+			return "", err
+		}
+	}
+
+	for _, hash := range hashes {
+		if threat, ok := s.cache[hash[:4]][hash]; ok {
+			return "safe browsing: " + threat, nil
+		}
+	}
+	return "", nil
+}
+
+// search fetches the full hashes matching the given prefixes and caches them.
+func (s *safeBrowsing) search(ctx context.Context, prefixes []string) error {
+	query := url.Values{"key": {s.key}}
+	for _, prefix := range prefixes {
+		query.Add("hashPrefixes", base64.StdEncoding.EncodeToString([]byte(prefix)))
+		s.cache[prefix] = make(map[string]string)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, safeBrowsingSearchURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("safe browsing API returned %s", resp.Status)
+	}
+
+	var result struct {
+		FullHashes []struct {
+			FullHash        string `json:"fullHash"`
+			FullHashDetails []struct {
+				ThreatType string `json:"threatType"`
+			} `json:"fullHashDetails"`
+		} `json:"fullHashes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding safe browsing response: %w", err)
+	}
+	for _, match := range result.FullHashes {
+		hash, err := base64.StdEncoding.DecodeString(match.FullHash)
+		if err != nil || len(hash) < 4 || len(match.FullHashDetails) == 0 {
+			continue
+		}
+		if entries, ok := s.cache[string(hash[:4])]; ok {
+			entries[string(hash)] = match.FullHashDetails[0].ThreatType
+		}
+	}
+	return nil
+}
+
+// safeBrowsingHashes returns the SHA-256 hashes of the host suffix and path prefix
+// expressions Safe Browsing matches a URL against.
+func safeBrowsingHashes(rawURL string) []string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return nil
+	}
+
+	// canonicalize the host and path.
+	host := strings.Trim(strings.ToLower(u.Hostname()), ".")
+	p := u.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	if cleaned := path.Clean(p); cleaned != p {
+		if strings.HasSuffix(p, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+		p = cleaned
+	}
+
+	// the exact host plus up to four suffixes, starting with the last five components and
+	// dropping leading ones down to two.
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		parts := strings.Split(host, ".")
+		if len(parts) > 5 {
+			parts = parts[len(parts)-5:]
+		}
+		for i := 0; i < len(parts)-1; i++ {
+			if suffix := strings.Join(parts[i:], "."); suffix != host {
+				hosts = append(hosts, suffix)
+			}
+		}
+	}
+
+	// the exact path with and without the query, plus up to four leading directories.
+	paths := []string{p}
+	if u.RawQuery != "" {
+		paths = append([]string{p + "?" + u.RawQuery}, paths...)
+	}
+	prefix := "/"
+	for i, part := range strings.Split(strings.Trim(p, "/"), "/") {
+		if i >= 4 || prefix == p {
+			break
+		}
+		paths = append(paths, prefix)
+		prefix += part + "/"
+	}
+
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, h := range hosts {
+		for _, p := range paths {
+			if expr := h + p; !seen[expr] {
+				seen[expr] = true
+				sum := sha256.Sum256([]byte(expr))
+				hashes = append(hashes, string(sum[:]))
+			}
+		}
+	}
+	return hashes
+}
+
+// blocklist flags bookmarks pointing at listed hosts (and their subdomains) or URL prefixes.
+type blocklist struct {
+	hosts    map[string]bool
+	prefixes []string
+}
+
+// loadBlocklist reads a blocklist file; blank lines and lines starting with # are ignored.
+func loadBlocklist(path string) (*blocklist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading blocklist: %w", err)
+	}
+	defer file.Close()
+
+	list := &blocklist{hosts: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.Contains(line, "://"):
+			list.prefixes = append(list.prefixes, line)
+		default:
+			list.hosts[strings.ToLower(line)] = true
+		}
+	}
+	return list, scanner.Err()
+}
+
+// digest identifies the contents of the blocklist, whatever order its entries came in.
+func (l *blocklist) digest() string {
+	entries := append([]string(nil), l.prefixes...)
+	for host := range l.hosts {
+		entries = append(entries, host)
+	}
+	sort.Strings(entries)
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (l *blocklist) check(ctx context.Context, b *Bookmark) (string, error) {
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(b.URL, prefix) {
+			return "blocklist: " + prefix, nil
+		}
+	}
+	if u, err := url.Parse(b.URL); err == nil {
+		for host := strings.ToLower(u.Hostname()); host != ""; {
+			if l.hosts[host] {
+				return "blocklist: " + host, nil
+			}
+			dot := strings.IndexByte(host, '.')
+			if dot < 0 {
+				break
+			}
+			host = host[dot+1:]
+		}
+	}
+	return "", nil
+}
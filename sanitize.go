@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unsafeSchemes lists URL schemes that run code or embed content when a bookmark is opened.
+var unsafeSchemes = []string{"javascript:", "data:", "vbscript:"}
+
+// sanitizeURLs flags or strips bookmarks whose URL uses an unsafe scheme, since
+// re-exported HTML containing them is an XSS risk when opened locally. Bookmarks
+// whose title or URL prefix appears in allow are intentional bookmarklets and kept.
+func sanitizeURLs(root *Bookmark, mode string, allow []string) error {
+	if mode != "keep" && mode != "flag" && mode != "strip" {
+		return fmt.Errorf("unknown unsafe URL mode %q", mode)
+	}
+	if mode == "keep" {
+		return nil
+	}
+
+	// allowed reports whether the bookmark matches an allowlist entry.
+	allowed := func(b *Bookmark) bool {
+		for _, entry := range allow {
+			if b.Title == entry || strings.HasPrefix(b.URL, entry) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var sanitize func(folder *Bookmark, path []string)
+	sanitize = func(folder *Bookmark, path []string) {
+		path = append(path, folder.Title)
+		kept := folder.Bookmarks[:0]
+		for _, b := range folder.Bookmarks {
+			if b.isFolder() {
+				sanitize(&b, path)
+			} else if isUnsafeURL(b.URL) && !allowed(&b) {
+				warnf("unsafe URL in %s: %q", folderPath(path), b.Title)
+				if mode == "strip" {
+					currentReport.count("dropped", 1)
+					continue
+				}
+				b.Unsafe = true
+			}
+			kept = append(kept, b)
+		}
+		folder.Bookmarks = kept
+	}
+	sanitize(root, nil)
+	return nil
+}
+
+// isUnsafeURL reports whether the URL uses a script-capable scheme. Browsers ignore
+// leading whitespace and embedded tabs or newlines in the scheme, so those are removed
+// before comparing.
+func isUnsafeURL(url string) bool {
+	scheme := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, strings.TrimLeftFunc(url, func(r rune) bool { return r <= ' ' }))
+	scheme = strings.ToLower(scheme)
+	for _, prefix := range unsafeSchemes {
+		if strings.HasPrefix(scheme, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule returns the time of the next run after t.
+type schedule func(t time.Time) time.Time
+
+// parseSchedule reads an -every value: either a duration such as 6h, or a cron expression
+// of five fields (minute, hour, day of month, month, day of week) in local time.
+func parseSchedule(spec string) (schedule, error) {
+	if interval, err := time.ParseDuration(spec); err == nil {
+		if interval <= 0 {
+			return nil, fmt.Errorf("schedule interval must be positive")
+		}
+		return func(t time.Time) time.Time { return t.Add(interval) }, nil
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q is neither a duration nor a cron expression", spec)
+	}
+	var c cronSchedule
+	var err error
+	limits := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	sets := [5]*[]bool{&c.minutes, &c.hours, &c.days, &c.months, &c.weekdays}
+	for i, field := range fields {
+		if *sets[i], err = parseCronField(field, limits[i][0], limits[i][1]); err != nil {
+			return nil, fmt.Errorf("error parsing schedule %q: %w", spec, err)
+		}
+	}
+	// Sunday is both 0 and 7.
+	c.weekdays[0] = c.weekdays[0] || c.weekdays[7]
+	c.anyDay, c.anyWeekday = fields[2] == "*", fields[4] == "*"
+	return c.next, nil
+}
+
+// cronSchedule holds the values each field of a cron expression matches, indexed by value.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays []bool
+	anyDay, anyWeekday                     bool
+}
+
+// parseCronField reads a comma-separated list of values, ranges, and * for the whole
+// range, each optionally followed by /step.
+func parseCronField(field string, min, max int) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return nil, fmt.Errorf("bad step in %q", part)
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(first); err != nil {
+				return nil, fmt.Errorf("bad value in %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(last); err != nil {
+					return nil, fmt.Errorf("bad value in %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// next returns the first minute after t the expression matches. As in cron, a day
+// matches when either the day of month or the day of week does if both are restricted.
+func (c cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// every combination recurs within a few years, so give up on expressions such as
+	// February 31st that never match.
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case !c.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !c.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !c.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the expression.
+func (c cronSchedule) matchesDay(t time.Time) bool {
+	day, weekday := c.days[t.Day()], c.weekdays[t.Weekday()]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+// runScheduled calls run at every time the schedule names until ctx is done. A failed run
+// is reported on stderr and retried at the next scheduled time.
+func runScheduled(ctx context.Context, next schedule, run func(ctx context.Context) error) error {
+	for {
+		at := next(time.Now())
+		if at.IsZero() {
+			return fmt.Errorf("schedule never matches again")
+		}
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		if err := run(ctx); err != nil && ctx.Err() == nil {
+			warnf("scheduled run failed: %s", err)
+		}
+	}
+}
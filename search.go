@@ -0,0 +1,25 @@
+package main
+
+import "strings"
+
+// searchResult is a bookmark matching a search, along with the folder holding it.
+type searchResult struct {
+	Bookmark *Bookmark
+	Folder   string
+}
+
+// searchBookmarks returns the links whose title or URL contains the query, ignoring case,
+// in document order. A positive limit caps the number of results.
+func searchBookmarks(root *Bookmark, query string, limit int) []searchResult {
+	query = strings.ToLower(query)
+	var results []searchResult
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() || (limit > 0 && len(results) >= limit) {
+			return
+		}
+		if strings.Contains(strings.ToLower(b.Title), query) || strings.Contains(strings.ToLower(b.URL), query) {
+			results = append(results, searchResult{Bookmark: b, Folder: folderPath(path)})
+		}
+	})
+	return results
+}
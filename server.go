@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/onntztzf/parse-bookmarks/api"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// apiServer implements the HTTP API described by api/openapi.json over one export, the
+// default collection, and the named collections below it. The tree is replaced as a
+// whole when the input is re-read.
+type apiServer struct {
+	served atomic.Pointer[servedTree] // nil when only named collections are served.
+
+	path   string // input file, or URL.
+	load   func(ctx context.Context) (Bookmark, error)
+	loadMu sync.Mutex // keeps reloads from storing trees out of order.
+
+	token       string                // bearer token required to read the collection, if any.
+	collections map[string]*apiServer // named collections, served under /collections/{name}.
+}
+
+// servedTree is a tree along with the validators of responses derived from it.
+type servedTree struct {
+	tree     *Bookmark
+	etag     string    // hash of the tree, quoted as in the ETag header.
+	modified time.Time // modification time of the input file, or when it was read.
+}
+
+// reload reads the input again and serves the new tree, keeping the previous one when
+// reading fails.
+func (s *apiServer) reload(ctx context.Context) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	modified := time.Now()
+	if info, err := os.Stat(s.path); err == nil {
+		modified = info.ModTime()
+	}
+	tree, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(&tree)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	s.served.Store(&servedTree{tree: &tree, etag: `"` + hex.EncodeToString(sum[:16]) + `"`, modified: modified})
+	return nil
+}
+
+// tree returns the tree being served.
+func (s *apiServer) tree() *Bookmark {
+	return s.served.Load().tree
+}
+
+// watch reloads the input whenever the modification time or size of the input file
+// changes, checking every second.
+func (s *apiServer) watch(ctx context.Context) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+	lastTime, lastSize := stat()
+	runScheduled(ctx, func(t time.Time) time.Time { return t.Add(time.Second) }, func(ctx context.Context) error {
+		modTime, size := stat()
+		if size < 0 || modTime.Equal(lastTime) && size == lastSize {
+			return nil
+		}
+		lastTime, lastSize = modTime, size
+		return s.reload(ctx)
+	})
+}
+
+// loadCollections returns a collection for every export in dir, named after its file
+// without extensions. A collection whose export has a NAME.token file next to it
+// requires the bearer token the file holds.
+func loadCollections(ctx context.Context, dir string, load func(ctx context.Context, path string) (Bookmark, error)) (map[string]*apiServer, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading collections: %w", err)
+	}
+	collections := make(map[string]*apiServer)
+	for _, entry := range entries {
+		file := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(file, ".") || filepath.Ext(file) == ".token" {
+			continue
+		}
+		name := strings.TrimSuffix(file, ".gz")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if _, ok := collections[name]; ok {
+			return nil, fmt.Errorf("error reading collections: several exports are named %q", name)
+		}
+		path := filepath.Join(dir, file)
+		c := &apiServer{path: path, load: func(ctx context.Context) (Bookmark, error) { return load(ctx, path) }}
+		if token, err := os.ReadFile(filepath.Join(dir, name+".token")); err == nil {
+			// an empty token file would otherwise make the collection public.
+			if c.token = strings.TrimSpace(string(token)); c.token == "" {
+				return nil, fmt.Errorf("error reading collections: %s.token is empty", name)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading collections: %w", err)
+		}
+		if err := c.reload(ctx); err != nil {
+			return nil, fmt.Errorf("error reading collection %s: %w", name, err)
+		}
+		collections[name] = c
+	}
+	return collections, nil
+}
+
+// forEach calls fn for the default collection, when there is one, and every named one.
+func (s *apiServer) forEach(fn func(c *apiServer)) {
+	if s.served.Load() != nil {
+		fn(s)
+	}
+	for _, c := range s.collections {
+		fn(c)
+	}
+}
+
+// collection returns the named collection, or the default one for an empty name. When
+// it does not exist or the request lacks its token, or for a GET request whose client
+// already has the response for the served tree, it writes the response itself and
+// returns false. Unknown names get the same response as a missing token, so collection
+// names cannot be probed for. Responses derived from the tree carry ETag and
+// Last-Modified headers.
+func (s *apiServer) collection(w http.ResponseWriter, r *http.Request, name string) (*apiServer, bool) {
+	c := s
+	if name != "" {
+		c = s.collections[name]
+	}
+	if name == "" && c.served.Load() == nil {
+		writeAPIError(w, http.StatusNotFound, "only named collections are served, under /collections/{name}")
+		return nil, false
+	}
+	if c == nil || c.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if c == nil || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return nil, false
+		}
+	}
+	if r.Method != http.MethodGet {
+		return c, true
+	}
+	served := c.served.Load()
+	w.Header().Set("ETag", served.etag)
+	w.Header().Set("Last-Modified", served.modified.UTC().Format(http.TimeFormat))
+	if notModified(r, served) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil, false
+	}
+	return c, true
+}
+
+// notModified reports whether the request's conditions show the client already has the
+// response for the served tree. If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, served *servedTree) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, etag := range strings.Split(match, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == served.etag || etag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !served.modified.Truncate(time.Second).After(since)
+}
+
+// runServe implements the serve subcommand, serving the input file over HTTP.
+func runServe(fs *flag.FlagSet) func(ctx context.Context) error {
+	loadFile := addInputFileFlags(fs)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	every := fs.String("every", "", "re-read the input on this schedule, a duration such as 6h or a cron expression")
+	watch := fs.Bool("watch", false, "re-read the input file whenever it changes")
+	collectionsDir := fs.String("collections", "", "also serve every export in this directory as a collection named after the file, under /collections/{name}; NAME.token files hold a collection's bearer token")
+	return func(ctx context.Context) error {
+		var next schedule
+		if *every != "" {
+			var err error
+			if next, err = parseSchedule(*every); err != nil {
+				return err
+			}
+		}
+
+		// with collections, the default collection is only served when an input is named.
+		s := &apiServer{path: inputPath(fs), load: func(ctx context.Context) (Bookmark, error) {
+			return loadFile(ctx, inputPath(fs))
+		}}
+		if *collectionsDir == "" || fs.NArg() > 0 {
+			if err := s.reload(ctx); err != nil {
+				return err
+			}
+		}
+		if *collectionsDir != "" {
+			var err error
+			if s.collections, err = loadCollections(ctx, *collectionsDir, loadFile); err != nil {
+				return err
+			}
+		}
+		// every scheduled reload of the collections is reported as a run of its own, the
+		// first one being the initial load.
+		if next != nil {
+			if err := currentReport.runEnded(nil); err != nil {
+				return err
+			}
+			go runScheduled(ctx, next, func(ctx context.Context) error {
+				var errs []error
+				s.forEach(func(c *apiServer) {
+					if err := c.reload(ctx); err != nil {
+						errs = append(errs, err)
+					}
+				})
+				err := errors.Join(errs...)
+				if err := currentReport.runEnded(err); err != nil {
+					warnf("%s", err)
+				}
+				return err
+			})
+		}
+		if *watch {
+			s.forEach(func(c *apiServer) { go c.watch(ctx) })
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", promhttp.Handler())
+		handler := api.HandlerWithOptions(s, api.StdHTTPServerOptions{
+			BaseRouter:  mux,
+			Middlewares: []api.MiddlewareFunc{instrumentHTTP},
+			ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+			},
+		})
+		fmt.Printf("serving HTTP on %s\n", *addr)
+
+		// stop accepting requests when interrupted, letting those in flight finish.
+		server := &http.Server{Addr: *addr, Handler: handler}
+		go func() {
+			<-ctx.Done()
+			server.Shutdown(context.Background())
+		}()
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
+}
+
+func (s *apiServer) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	s.getBookmarks(w, r, "")
+}
+
+func (s *apiServer) GetCollectionBookmarks(w http.ResponseWriter, r *http.Request, name string) {
+	s.getBookmarks(w, r, name)
+}
+
+func (s *apiServer) getBookmarks(w http.ResponseWriter, r *http.Request, name string) {
+	if c, ok := s.collection(w, r, name); ok {
+		writeAPIResponse(w, toAPI(c.tree()))
+	}
+}
+
+func (s *apiServer) SearchBookmarks(w http.ResponseWriter, r *http.Request, params api.SearchBookmarksParams) {
+	s.search(w, r, "", params.Q, params.Limit)
+}
+
+func (s *apiServer) SearchCollection(w http.ResponseWriter, r *http.Request, name string, params api.SearchCollectionParams) {
+	s.search(w, r, name, params.Q, params.Limit)
+}
+
+func (s *apiServer) search(w http.ResponseWriter, r *http.Request, name, q string, limitParam *int) {
+	c, ok := s.collection(w, r, name)
+	if !ok {
+		return
+	}
+	// the generated code checks presence and types; the spec's bounds are checked here.
+	if q == "" {
+		writeAPIError(w, http.StatusBadRequest, "query parameter q must not be empty")
+		return
+	}
+	limit := 0
+	if limitParam != nil {
+		if limit = *limitParam; limit < 0 {
+			writeAPIError(w, http.StatusBadRequest, "query parameter limit must not be negative")
+			return
+		}
+	}
+
+	resp := api.SearchResponse{Results: []api.SearchResult{}}
+	for _, result := range searchBookmarks(c.tree(), q, limit) {
+		resp.Results = append(resp.Results, api.SearchResult{Bookmark: toAPI(result.Bookmark), Folder: result.Folder})
+	}
+	writeAPIResponse(w, resp)
+}
+
+func (s *apiServer) ExportBookmarks(w http.ResponseWriter, r *http.Request, params api.ExportBookmarksParams) {
+	s.export(w, r, "", params.Format)
+}
+
+func (s *apiServer) ExportCollection(w http.ResponseWriter, r *http.Request, name string, params api.ExportCollectionParams) {
+	s.export(w, r, name, params.Format)
+}
+
+func (s *apiServer) export(w http.ResponseWriter, r *http.Request, name, format string) {
+	c, ok := s.collection(w, r, name)
+	if !ok {
+		return
+	}
+	write, ok := formats[format]
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q", format))
+		return
+	}
+
+	var output bytes.Buffer
+	if err := write(&output, c.tree()); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(output.Bytes())
+}
+
+func (s *apiServer) ParseBookmarks(w http.ResponseWriter, r *http.Request) {
+	tree, err := parseHTML(r.Context(), http.MaxBytesReader(w, r.Body, 64<<20), defaultRootTitle)
+	observeParse(&tree, err)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeAPIResponse(w, toAPI(&tree))
+}
+
+func (s *apiServer) ReloadBookmarks(w http.ResponseWriter, r *http.Request) {
+	s.reloadCollection(w, r, "")
+}
+
+func (s *apiServer) ReloadCollection(w http.ResponseWriter, r *http.Request, name string) {
+	s.reloadCollection(w, r, name)
+}
+
+func (s *apiServer) reloadCollection(w http.ResponseWriter, r *http.Request, name string) {
+	c, ok := s.collection(w, r, name)
+	if !ok {
+		return
+	}
+	if err := c.reload(r.Context()); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *apiServer) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(api.Spec)
+}
+
+// writeAPIResponse writes a successful JSON response.
+func writeAPIResponse(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeAPIError writes an error response in the shape of the spec's Error schema.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.Error{Error: message})
+}
+
+// toAPI converts a bookmark and its entries to the generated API type.
+func toAPI(b *Bookmark) api.Bookmark {
+	optional := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+
+	a := api.Bookmark{
+		Title:       b.Title,
+		Url:         optional(b.URL),
+		AddAt:       b.AddAt,
+		UpdateAt:    b.UpdateAt,
+		ContentType: optional(b.ContentType),
+		FinalUrl:    optional(b.FinalURL),
+		Lang:        optional(b.Lang),
+		Thumbnail:   optional(b.Thumbnail),
+		Description: optional(b.Description),
+		Icon:        optional(b.Icon),
+		Archive:     optional(b.Archive),
+		Article:     optional(b.Article),
+		OriginalUrl: optional(b.OriginalURL),
+
+		MetaDescription: optional(b.MetaDescription),
+		OgTitle:         optional(b.OGTitle),
+		OgImage:         optional(b.OGImage),
+	}
+	if b.Unsafe {
+		a.Unsafe = &b.Unsafe
+	}
+	if len(b.Tags) > 0 {
+		a.Tags = &b.Tags
+	}
+	if b.Private {
+		a.Private = &b.Private
+	}
+	if len(b.Extra) > 0 {
+		a.Extra = &b.Extra
+	}
+	if b.Special != "" {
+		special := api.BookmarkSpecial(b.Special)
+		a.Special = &special
+	}
+	if b.Status != 0 {
+		a.Status = &b.Status
+	}
+	if b.Visits != 0 {
+		a.Visits = &b.Visits
+	}
+	if b.WordCount != 0 {
+		a.WordCount = &b.WordCount
+		a.ReadingTime = &b.ReadingTime
+	}
+	a.LastVisit = b.LastVisit
+	a.Count = b.Count
+	a.DeepCount = b.DeepCount
+	a.NewestAddAt = b.NewestAddAt
+	if b.Source != nil {
+		a.Source = &api.Source{Name: b.Source.Name, Path: b.Source.Path, Folder: b.Source.Folder}
+	}
+	if b.Frecency != 0 {
+		a.Frecency = &b.Frecency
+	}
+	if len(b.Bookmarks) > 0 {
+		children := make([]api.Bookmark, len(b.Bookmarks))
+		for i := range b.Bookmarks {
+			children[i] = toAPI(&b.Bookmarks[i])
+		}
+		a.Bookmarks = &children
+	}
+	return a
+}
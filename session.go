@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// sessionExport is a saved tab session export: Session Buddy's format holds sessions of
+// windows, while tab-group exporters save the windows of a single session.
+type sessionExport struct {
+	Sessions []struct {
+		Name    string          `json:"name"`
+		Created int64           `json:"created"` // milliseconds since the epoch.
+		Windows []sessionWindow `json:"windows"`
+	} `json:"sessions"`
+	Windows []sessionWindow `json:"windows"`
+}
+
+// sessionWindow is a browser window of a saved session.
+type sessionWindow struct {
+	Tabs []struct {
+		URL     string `json:"url"`
+		Title   string `json:"title"`
+		GroupID int    `json:"groupId"` // Chrome uses -1 for tabs outside a group.
+	} `json:"tabs"`
+	// Session Buddy calls the tab groups tabGroups; other exporters call them groups.
+	TabGroups []sessionGroup `json:"tabGroups"`
+	Groups    []sessionGroup `json:"groups"`
+}
+
+// sessionGroup is a Chrome tab group.
+type sessionGroup struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Color string `json:"color"`
+}
+
+// isSessionJSON reports whether an export starts like a tab session JSON document.
+func isSessionJSON(head []byte) bool {
+	head = bytes.TrimSpace(head)
+	return bytes.HasPrefix(head, []byte("{")) &&
+		(bytes.Contains(head, []byte(`"sessions"`)) || bytes.Contains(head, []byte(`"windows"`)))
+}
+
+// parseSessionJSON parses a Session Buddy or tab-group session export into a root folder
+// titled rootTitle. Sessions and, when a session has several, windows become folders, and
+// every tab group becomes a folder where its first tab was.
+func parseSessionJSON(ctx context.Context, r io.Reader, rootTitle string) (Bookmark, error) {
+	var export sessionExport
+	if err := json.NewDecoder(contextReader{ctx, r}).Decode(&export); err != nil {
+		return Bookmark{}, fmt.Errorf("error parsing session JSON: %w", err)
+	}
+
+	root := Bookmark{Title: rootTitle}
+	if len(export.Sessions) == 0 {
+		root.Bookmarks = sessionWindows(export.Windows)
+		return root, nil
+	}
+	for i, session := range export.Sessions {
+		folder := Bookmark{Title: session.Name, Bookmarks: sessionWindows(session.Windows)}
+		if session.Created > 0 {
+			created := time.UnixMilli(session.Created)
+			folder.AddAt = &created
+		}
+		if folder.Title == "" {
+			folder.Title = fmt.Sprintf("Session %d", i+1)
+		}
+		root.Bookmarks = append(root.Bookmarks, folder)
+	}
+	return root, nil
+}
+
+// sessionWindows returns the entries of a session's windows, with a folder per window
+// when there is more than one.
+func sessionWindows(windows []sessionWindow) []Bookmark {
+	if len(windows) == 1 {
+		return windowTabs(windows[0])
+	}
+	var folders []Bookmark
+	for i, window := range windows {
+		folders = append(folders, Bookmark{Title: fmt.Sprintf("Window %d", i+1), Bookmarks: windowTabs(window)})
+	}
+	return folders
+}
+
+// windowTabs returns the tabs of a window in order, gathering grouped tabs into a folder
+// per group.
+func windowTabs(window sessionWindow) []Bookmark {
+	groups := make(map[int]sessionGroup)
+	for _, group := range append(window.TabGroups, window.Groups...) {
+		groups[group.ID] = group
+	}
+
+	var entries []Bookmark
+	folders := make(map[int]int) // group ID to index of its folder in entries.
+	for _, tab := range window.Tabs {
+		b := Bookmark{Title: tab.Title, URL: tab.URL}
+		if b.Title == "" {
+			b.Title = tab.URL
+		}
+		if tab.GroupID <= 0 {
+			entries = append(entries, b)
+			continue
+		}
+		i, ok := folders[tab.GroupID]
+		if !ok {
+			// unnamed groups are known by their color.
+			title := groups[tab.GroupID].Title
+			if title == "" {
+				title = groups[tab.GroupID].Color + " group"
+			}
+			entries = append(entries, Bookmark{Title: title})
+			i = len(entries) - 1
+			folders[tab.GroupID] = i
+		}
+		entries[i].Bookmarks = append(entries[i].Bookmarks, b)
+	}
+	return entries
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// titleCluster holds links whose titles are similar enough to be the same article.
+type titleCluster struct {
+	members []*Bookmark
+	paths   []string
+}
+
+// commonWordShare is the share of titles above which a word, such as a site name most
+// links of a collection carry, pairs too many titles to be worth comparing them by.
+const commonWordShare = 0.05
+
+// minCommonWordTitles keeps words from counting as common in small collections.
+const minCommonWordTitles = 20
+
+// titleEntry is a link considered for title clustering.
+type titleEntry struct {
+	b      *Bookmark
+	path   string
+	key    string   // canonical URL, so links already known to be duplicates are not paired.
+	words  []string // distinct words of the title.
+	folded []rune   // the title's words joined by single spaces.
+}
+
+// findSimilarTitles clusters the links of the tree whose titles are at least threshold
+// similar, where similarity is the better of the titles' word overlap and their edit
+// distance relative to the longer title. Only titles sharing a word other than a stopword
+// or a word common across the collection are compared, and only when their lengths are
+// close enough for them to reach threshold.
+func findSimilarTitles(root *Bookmark, threshold float64) []*titleCluster {
+	var entries []titleEntry
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() {
+			return
+		}
+		words := titleWords(b.Title)
+		if len(words) == 0 {
+			return
+		}
+		key, _ := canonicalURL(b.URL)
+		entries = append(entries, titleEntry{
+			b:      b,
+			path:   folderPath(path),
+			key:    key,
+			words:  distinct(words),
+			folded: []rune(strings.Join(words, " ")),
+		})
+	})
+
+	// union the entries of every similar pair.
+	parent := make([]int, len(entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	titles := make(map[string]int)
+	for _, e := range entries {
+		for _, word := range e.words {
+			titles[word]++
+		}
+	}
+	stop := stopwordSet()
+	common := int(commonWordShare * float64(len(entries)))
+	if common < minCommonWordTitles {
+		common = minCommonWordTitles
+	}
+	byWord := make(map[string][]int)
+	for i, e := range entries {
+		compared := make(map[int]bool)
+		for _, word := range e.words {
+			// stopwords and common words pair titles that are rarely the same article.
+			if stop[word] || titles[word] > common {
+				continue
+			}
+			for _, j := range byWord[word] {
+				if compared[j] {
+					continue
+				}
+				compared[j] = true
+				if e.key != entries[j].key && closeInLength(e, entries[j], threshold) && titleSimilarity(e, entries[j]) >= threshold {
+					parent[find(i)] = find(j)
+				}
+			}
+			byWord[word] = append(byWord[word], i)
+		}
+	}
+
+	// collect the clusters in the order their first links appear.
+	var clusters []*titleCluster
+	byRoot := make(map[int]*titleCluster)
+	for i, e := range entries {
+		cluster, ok := byRoot[find(i)]
+		if !ok {
+			cluster = &titleCluster{}
+			byRoot[find(i)] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.members = append(cluster.members, e.b)
+		cluster.paths = append(cluster.paths, e.path)
+	}
+	similar := clusters[:0]
+	for _, cluster := range clusters {
+		if len(cluster.members) > 1 {
+			similar = append(similar, cluster)
+		}
+	}
+	return similar
+}
+
+// titleWords splits a title into lowercase words, ignoring punctuation.
+func titleWords(title string) []string {
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// distinct returns words without repeats, keeping their order.
+func distinct(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	var out []string
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			out = append(out, word)
+		}
+	}
+	return out
+}
+
+// closeInLength reports whether two titles are close enough in length to be threshold
+// similar: their word overlap is at most the ratio of their word counts, and their edit
+// similarity at most the ratio of their lengths.
+func closeInLength(a, b titleEntry, threshold float64) bool {
+	return lengthRatio(len(a.words), len(b.words)) >= threshold ||
+		lengthRatio(len(a.folded), len(b.folded)) >= threshold
+}
+
+// lengthRatio returns the shorter of two lengths relative to the longer.
+func lengthRatio(a, b int) float64 {
+	if a > b {
+		a, b = b, a
+	}
+	return float64(a) / float64(b)
+}
+
+// titleSimilarity scores how alike two titles are, from 0 to 1.
+func titleSimilarity(a, b titleEntry) float64 {
+	shared := 0
+	words := make(map[string]bool, len(a.words))
+	for _, word := range a.words {
+		words[word] = true
+	}
+	for _, word := range b.words {
+		if words[word] {
+			shared++
+		}
+	}
+	overlap := float64(shared) / float64(len(a.words)+len(b.words)-shared)
+
+	longer := len(a.folded)
+	if len(b.folded) > longer {
+		longer = len(b.folded)
+	}
+	edit := 1 - float64(editDistance(a.folded, b.folded))/float64(longer)
+	if edit > overlap {
+		return edit
+	}
+	return overlap
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			next := diagonal + cost
+			if row[j]+1 < next {
+				next = row[j] + 1
+			}
+			if row[j-1]+1 < next {
+				next = row[j-1] + 1
+			}
+			diagonal, row[j] = row[j], next
+		}
+	}
+	return row[len(b)]
+}
+
+// writeTitleReport writes each cluster of links with similar titles.
+func writeTitleReport(w io.Writer, clusters []*titleCluster) {
+	total := 0
+	for _, cluster := range clusters {
+		fmt.Fprintf(w, "%s (%d links)\n", cluster.members[0].Title, len(cluster.members))
+		for i, b := range cluster.members {
+			fmt.Fprintf(w, "\t%s\t%s\t%s\n", cluster.paths[i], b.Title, b.URL)
+		}
+		total += len(cluster.members)
+	}
+	fmt.Fprintf(w, "%d clusters, %d links\n", len(clusters), total)
+}
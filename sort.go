@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// sortBookmarks sorts the entries of every folder in place. by is "title", compared with the
+// Unicode collation rules of locale (a BCP 47 tag such as "de" or "sv"; empty selects the
+// root collation), "added", comparing the time entries were added with undated ones last,
+// or "visits" and "frecency", putting the most used entries first.
+func sortBookmarks(root *Bookmark, by, locale string) error {
+	var less func(a, b *Bookmark) bool
+	switch by {
+	case "title":
+		tag := language.Und
+		if locale != "" {
+			var err error
+			if tag, err = language.Parse(locale); err != nil {
+				return fmt.Errorf("invalid locale %q: %w", locale, err)
+			}
+		}
+		collator := collate.New(tag)
+		less = func(a, b *Bookmark) bool {
+			return collator.CompareString(a.Title, b.Title) < 0
+		}
+	case "added":
+		less = func(a, b *Bookmark) bool {
+			if a.AddAt == nil || b.AddAt == nil {
+				return a.AddAt != nil && b.AddAt == nil
+			}
+			return a.AddAt.Before(*b.AddAt)
+		}
+	case "visits":
+		less = func(a, b *Bookmark) bool { return a.Visits > b.Visits }
+	case "frecency":
+		less = func(a, b *Bookmark) bool { return a.Frecency > b.Frecency }
+	default:
+		return fmt.Errorf("unknown sort order %q", by)
+	}
+
+	var sortFolder func(folder *Bookmark)
+	sortFolder = func(folder *Bookmark) {
+		sort.SliceStable(folder.Bookmarks, func(i, j int) bool {
+			return less(&folder.Bookmarks[i], &folder.Bookmarks[j])
+		})
+		for i := range folder.Bookmarks {
+			if folder.Bookmarks[i].isFolder() {
+				sortFolder(&folder.Bookmarks[i])
+			}
+		}
+	}
+	sortFolder(root)
+	return nil
+}
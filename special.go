@@ -0,0 +1,119 @@
+package main
+
+import "strings"
+
+// canonical roles of the folders browsers create themselves.
+const (
+	specialToolbar = "toolbar"
+	specialMenu    = "menu"
+	specialMobile  = "mobile"
+	specialOther   = "other"
+)
+
+// specialTitles are the canonical titles given to special folders when normalizing.
+var specialTitles = map[string]string{
+	specialToolbar: "Toolbar",
+	specialMenu:    "Menu",
+	specialMobile:  "Mobile",
+	specialOther:   "Other",
+}
+
+// specialFolderNames maps the lowercased titles browsers give their special folders, in
+// the locales seen in exports, to the folder's canonical role.
+var specialFolderNames = map[string]string{
+	"bookmarks bar":            specialToolbar,
+	"bookmarks toolbar":        specialToolbar,
+	"favorites bar":            specialToolbar,
+	"favourites bar":           specialToolbar,
+	"lesezeichenleiste":        specialToolbar,
+	"lesezeichen-symbolleiste": specialToolbar,
+	"barre de favoris":         specialToolbar,
+	"barre personnelle":        specialToolbar,
+	"barra de marcadores":      specialToolbar,
+	"barra dei preferiti":      specialToolbar,
+	"barra de favoritos":       specialToolbar,
+	"bladwijzerbalk":           specialToolbar,
+	"панель закладок":          specialToolbar,
+	"书签栏":                      specialToolbar,
+	"收藏夹栏":                     specialToolbar,
+	"ブックマーク バー":                specialToolbar,
+	"ブックマークバー":                 specialToolbar,
+	"북마크바":                     specialToolbar,
+
+	"bookmarks menu":        specialMenu,
+	"lesezeichen-menü":      specialMenu,
+	"menu des marque-pages": specialMenu,
+	"menú de marcadores":    specialMenu,
+	"menu dei segnalibri":   specialMenu,
+	"меню закладок":         specialMenu,
+	"书签菜单":                  specialMenu,
+	"ブックマークメニュー":            specialMenu,
+
+	"mobile bookmarks":     specialMobile,
+	"mobile lesezeichen":   specialMobile,
+	"favoris mobiles":      specialMobile,
+	"marque-pages mobiles": specialMobile,
+	"marcadores del móvil": specialMobile,
+	"segnalibri mobile":    specialMobile,
+	"мобильные закладки":   specialMobile,
+	"移动设备书签":               specialMobile,
+	"手机书签":                 specialMobile,
+	"モバイルのブックマーク":          specialMobile,
+	"모바일 북마크":              specialMobile,
+
+	"other bookmarks":     specialOther,
+	"other favorites":     specialOther,
+	"other favourites":    specialOther,
+	"unsorted bookmarks":  specialOther,
+	"weitere lesezeichen": specialOther,
+	"andere lesezeichen":  specialOther,
+	"autres favoris":      specialOther,
+	"autres marque-pages": specialOther,
+	"otros marcadores":    specialOther,
+	"altri preferiti":     specialOther,
+	"andere bladwijzers":  specialOther,
+	"другие закладки":     specialOther,
+	"其他书签":                specialOther,
+	"其他收藏夹":               specialOther,
+	"その他のブックマーク":          specialOther,
+	"기타 북마크":              specialOther,
+}
+
+// specialFromAttrs returns the role browsers declare on a folder through the
+// PERSONAL_TOOLBAR_FOLDER and UNFILED_BOOKMARKS_FOLDER attributes.
+func specialFromAttrs(attr func(name string) string) string {
+	switch {
+	case strings.EqualFold(attr("personal_toolbar_folder"), "true"):
+		return specialToolbar
+	case strings.EqualFold(attr("unfiled_bookmarks_folder"), "true"):
+		return specialOther
+	}
+	return ""
+}
+
+// normalizeSpecialFolders recognizes the special folders at the top of the tree by their
+// localized titles and records their canonical role. With retitle set, special folders
+// are also renamed to their canonical titles so that exports from different browsers and
+// locales line up when merged.
+func normalizeSpecialFolders(root *Bookmark, retitle bool) {
+	// special folders are either the root itself or one or two levels below it, depending
+	// on whether the export has a root folder of its own.
+	var normalize func(folder *Bookmark, depth int)
+	normalize = func(folder *Bookmark, depth int) {
+		if folder.Special == "" {
+			folder.Special = specialFolderNames[strings.ToLower(strings.TrimSpace(folder.Title))]
+		}
+		if retitle && folder.Special != "" {
+			folder.Title = specialTitles[folder.Special]
+		}
+		if depth == 2 {
+			return
+		}
+		for i := range folder.Bookmarks {
+			if folder.Bookmarks[i].isFolder() {
+				normalize(&folder.Bookmarks[i], depth+1)
+			}
+		}
+	}
+	normalize(root, 0)
+}
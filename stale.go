@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// archiveFolder is the top-level folder stale links are moved into, by year.
+const archiveFolder = "Archive"
+
+// parseAge reads an age such as 3y, 6w, or 90d, or any duration time.ParseDuration
+// accepts, and returns the time that long before now.
+func parseAge(s string, now time.Time) (time.Time, error) {
+	if n, err := strconv.Atoi(s[:max(len(s)-1, 0)]); err == nil && n >= 0 {
+		switch s[len(s)-1] {
+		case 'y':
+			return now.AddDate(-n, 0, 0), nil
+		case 'w':
+			return now.AddDate(0, 0, -7*n), nil
+		case 'd':
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid age %q, expected a number of years (3y), weeks (6w), days (90d), or a duration", s)
+	}
+	return now.Add(-d), nil
+}
+
+// lastActivity returns when the link was last added, modified, or visited, or nil if
+// the export records none of these.
+func lastActivity(b *Bookmark) *time.Time {
+	var last *time.Time
+	for _, t := range []*time.Time{b.AddAt, b.UpdateAt, b.LastVisit} {
+		if t != nil && (last == nil || t.After(*last)) {
+			last = t
+		}
+	}
+	return last
+}
+
+// archiveStale moves the links inactive since cutoff into Archive/<year>, by the year of
+// their last activity, or removes them when drop is set. Each link is reported to w, and
+// links without any timestamps or already archived are left alone. It returns the number
+// of links archived.
+func archiveStale(root *Bookmark, cutoff time.Time, drop bool, w io.Writer) int {
+	targets := make(map[*Bookmark][]string)
+	walkBookmarks(root, func(b *Bookmark, path []string) {
+		if b.isFolder() || len(path) > 1 && path[1] == archiveFolder {
+			return
+		}
+		last := lastActivity(b)
+		if last == nil || !last.Before(cutoff) {
+			return
+		}
+		action := "archived"
+		if drop {
+			action = "dropped"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", action, folderPath(path), b.Title, b.URL, last.Format("2006-01-02"))
+		targets[b] = []string{archiveFolder, strconv.Itoa(last.Year())}
+	})
+	if drop {
+		removeBookmarks(root, func(b *Bookmark) bool { return targets[b] != nil })
+	} else {
+		relocate(root, targets)
+	}
+	return len(targets)
+}
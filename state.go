@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runState remembers what the previous run derived from each bookmark, keyed by a hash of
+// the bookmark's input, so that unchanged bookmarks need not be checked or enriched again.
+// Entries are kept per namespace, which names the checks or enrichers that derived them
+// along with their options, so a run never takes over results of differently configured
+// runs sharing the state file. A nil *runState disables incremental processing.
+type runState struct {
+	path      string
+	namespace string
+	saved     map[string]map[string]stateEntry // the entries of every namespace in the file.
+	previous  map[string]stateEntry
+	mu        sync.Mutex
+	next      map[string]stateEntry
+}
+
+// stateEntry holds the results recorded for one bookmark.
+type stateEntry struct {
+	Fields   map[string]json.RawMessage `json:"fields,omitempty"`   // bookmark fields set by the run.
+	Problems []string                   `json:"problems,omitempty"` // problems reported by checks.
+}
+
+// loadRunState reads the entries of namespace from the state file at path; a missing
+// file starts from an empty state.
+func loadRunState(path, namespace string) (*runState, error) {
+	state := &runState{
+		path:      path,
+		namespace: namespace,
+		saved:     make(map[string]map[string]stateEntry),
+		next:      make(map[string]stateEntry),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &state.saved); err != nil {
+			return nil, fmt.Errorf("error parsing state file: %w", err)
+		}
+	}
+	state.previous = state.saved[namespace]
+	return state, nil
+}
+
+// lookup returns the results the previous run recorded for a bookmark with this hash.
+func (s *runState) lookup(hash string) (stateEntry, bool) {
+	if s == nil {
+		return stateEntry{}, false
+	}
+	entry, ok := s.previous[hash]
+	return entry, ok
+}
+
+// record stores the results of this run for a bookmark. It is safe for concurrent use.
+func (s *runState) record(hash string, entry stateEntry) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[hash] = entry
+}
+
+// save writes the entries recorded by this run, dropping bookmarks that disappeared. The
+// entries of other namespaces are kept as they were.
+func (s *runState) save() error {
+	if s == nil {
+		return nil
+	}
+	s.saved[s.namespace] = s.next
+	data, err := json.Marshal(s.saved)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	return nil
+}
+
+// bookmarkHash identifies a bookmark by the fields read from the export.
+func bookmarkHash(b *Bookmark) string {
+	unix := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	input := strings.Join([]string{b.Title, b.URL, unix(b.AddAt), unix(b.UpdateAt)}, "\x00")
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// EventKind identifies what a streaming parse Event describes.
+type EventKind int
+
+const (
+	// EventFolderStart opens a folder; the events up to the matching EventFolderEnd are its entries.
+	EventFolderStart EventKind = iota
+	// EventBookmark describes a link.
+	EventBookmark
+	// EventFolderEnd closes the most recently opened folder.
+	EventFolderEnd
+	// EventWarning reports a problem worked around, such as a malformed timestamp that
+	// was dropped. It precedes the event of the entry it concerns.
+	EventWarning
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventFolderStart:
+		return "FolderStart"
+	case EventBookmark:
+		return "Bookmark"
+	case EventFolderEnd:
+		return "FolderEnd"
+	case EventWarning:
+		return "Warning"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is emitted by ParseStream for each folder boundary and link in document order.
+type Event struct {
+	Kind EventKind
+	// Bookmark is the link, or the folder without its entries. It is empty for EventFolderEnd
+	// and EventWarning.
+	Bookmark Bookmark
+	// Path holds the titles of the folders enclosing the entry. It is reused for later
+	// events, so consumers keeping it must copy it.
+	Path []string
+	// Warning is the problem reported by EventWarning, located at the tag causing it.
+	Warning *Warning
+}
+
+// streamPosition tracks where the tokens read so far end in the document.
+type streamPosition struct {
+	offset, line, column int
+}
+
+// advance moves the position past raw, the bytes of a token.
+func (pos *streamPosition) advance(raw []byte) {
+	pos.offset += len(raw)
+	if i := bytes.LastIndexByte(raw, '\n'); i >= 0 {
+		pos.line += bytes.Count(raw, []byte("\n"))
+		pos.column = 1 + utf8.RuneCount(raw[i+1:])
+	} else {
+		pos.column += utf8.RuneCount(raw)
+	}
+}
+
+// ParseStream tokenizes an exported bookmarks document and calls emit for every folder
+// and link as soon as it is read, so that consumers never need the whole tree in memory.
+// Malformed timestamps are dropped and reported with an EventWarning, as lenient parsing
+// does. Of the options, only WithIcons applies. Parsing stops at the first error returned
+// by emit, which ParseStream returns, or when ctx is done.
+func ParseStream(ctx context.Context, r io.Reader, emit func(Event) error, opts ...Option) error {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	z := html.NewTokenizer(r)
+
+	// dls records, for each open DL, whether it holds the entries of a folder.
+	var dls []bool
+	var path []string
+	var pending *Bookmark // folder whose H3 was read but whose DL has not started yet.
+	var link *Bookmark    // link read last, emitted once its DD notes, if any, were read.
+	var notes *strings.Builder
+
+	// next reads the next token, keeping track of where it starts. Raw is read first, as
+	// reading the tag name may modify it.
+	pos := streamPosition{line: 1, column: 1}
+	var start streamPosition
+	var raw []byte
+	next := func() html.TokenType {
+		tt := z.Next()
+		raw = append(raw[:0], z.Raw()...)
+		start = pos
+		pos.advance(raw)
+		return tt
+	}
+
+	// timestamps converts the ADD_DATE and LAST_MODIFIED attributes of the tag just read,
+	// dropping malformed ones with an EventWarning.
+	timestamps := func(attrs map[string]string) (addAt, updateAt *time.Time, err error) {
+		times := []*time.Time{nil, nil}
+		for i, attr := range []string{"add_date", "last_modified"} {
+			t, err := parseTimestamp(attrs[attr])
+			if err != nil {
+				warning := &Warning{
+					Line:    start.line,
+					Column:  start.column,
+					Offset:  start.offset,
+					Snippet: snippet(raw),
+					Err:     fmt.Errorf("error parsing timestamp: %w", err),
+				}
+				if err := emit(Event{Kind: EventWarning, Path: path, Warning: warning}); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			times[i] = t
+		}
+		return times[0], times[1], nil
+	}
+
+	// openPending emits the pending folder; withEntries tells whether its DL follows.
+	openPending := func(withEntries bool) error {
+		if pending == nil {
+			return nil
+		}
+		folder := *pending
+		pending = nil
+		if err := emit(Event{Kind: EventFolderStart, Bookmark: folder, Path: path}); err != nil {
+			return err
+		}
+		if withEntries {
+			path = append(path, folder.Title)
+			return nil
+		}
+		return emit(Event{Kind: EventFolderEnd, Path: path})
+	}
+
+	// emitLink emits the last link read, with the notes that followed it.
+	emitLink := func() error {
+		if link == nil {
+			return nil
+		}
+		bookmark := *link
+		link = nil
+		if notes != nil {
+			bookmark.Description = strings.TrimSpace(notes.String())
+			notes = nil
+		}
+		return emit(Event{Kind: EventBookmark, Bookmark: bookmark, Path: path})
+	}
+
+	// readText collects the text up to the end tag of the element just opened.
+	readText := func(tag string) string {
+		var text strings.Builder
+		for {
+			switch next() {
+			case html.ErrorToken:
+				return text.String()
+			case html.TextToken:
+				text.Write(z.Text())
+			case html.EndTagToken:
+				if name, _ := z.TagName(); string(name) == tag {
+					return text.String()
+				}
+			}
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tt := next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				if err := emitLink(); err != nil {
+					return err
+				}
+				return openPending(false)
+			}
+			return fmt.Errorf("error parsing HTML: %w", z.Err())
+		}
+		if tt == html.TextToken && notes != nil {
+			notes.Write(z.Text())
+		}
+		if tt != html.StartTagToken && tt != html.EndTagToken {
+			continue
+		}
+
+		name, hasAttr := z.TagName()
+		attrs := make(map[string]string)
+		for hasAttr {
+			var key, value []byte
+			key, value, hasAttr = z.TagAttr()
+			attrs[string(key)] = string(value)
+		}
+
+		// a DD right after a link holds its notes, which run up to the next tag.
+		if tt == html.StartTagToken && string(name) == "dd" && link != nil && notes == nil {
+			notes = new(strings.Builder)
+			continue
+		}
+		if err := emitLink(); err != nil {
+			return err
+		}
+
+		switch {
+		case tt == html.StartTagToken && string(name) == "dl":
+			dls = append(dls, pending != nil)
+			if err := openPending(true); err != nil {
+				return err
+			}
+
+		case tt == html.EndTagToken && string(name) == "dl":
+			if err := openPending(false); err != nil {
+				return err
+			}
+			if len(dls) == 0 {
+				continue
+			}
+			isFolder := dls[len(dls)-1]
+			dls = dls[:len(dls)-1]
+			if isFolder {
+				path = path[:len(path)-1]
+				if err := emit(Event{Kind: EventFolderEnd, Path: path}); err != nil {
+					return err
+				}
+			}
+
+		case tt == html.StartTagToken && string(name) == "h3":
+			if err := openPending(false); err != nil {
+				return err
+			}
+			addAt, updateAt, err := timestamps(attrs)
+			if err != nil {
+				return err
+			}
+			pending = &Bookmark{
+				AddAt:    addAt,
+				UpdateAt: updateAt,
+				Special: specialFromAttrs(func(name string) string {
+					return attrs[name]
+				}),
+				Extra: extraAttrs(attrs, folderAttrs),
+			}
+			pending.Title = readText("h3")
+
+		case tt == html.StartTagToken && string(name) == "a":
+			if err := openPending(false); err != nil {
+				return err
+			}
+			addAt, updateAt, err := timestamps(attrs)
+			if err != nil {
+				return err
+			}
+			bookmark := Bookmark{
+				URL:      attrs["href"],
+				AddAt:    addAt,
+				UpdateAt: updateAt,
+				Tags:     parseTags(attrs["tags"]),
+				Private:  attrs["private"] == "1",
+				Extra:    extraAttrs(attrs, linkAttrs),
+			}
+			if o.icons {
+				bookmark.Icon = attrs["icon"]
+			}
+			bookmark.Title = readText("a")
+			link = &bookmark
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// syncFileName is the file holding the export in a sync repository.
+const syncFileName = "bookmarks.json"
+
+// runSync implements the sync subcommand, which records the bookmark tree in a git
+// repository, committing every change so the repository holds its history.
+func runSync(fs *flag.FlagSet) func(ctx context.Context) error {
+	load := addInputFlags(fs)
+	repo := fs.String("git", "", "git repository to write the export to and commit it in, created if missing")
+	webhook := fs.String("webhook", "", "URL to POST a JSON summary of the links added, removed, and modified to after each commit")
+	every := fs.String("every", "", "keep running, syncing again on this schedule, a duration such as 6h or a cron expression")
+	return func(ctx context.Context) error {
+		if *repo == "" {
+			return fmt.Errorf("sync needs a -git repository")
+		}
+
+		var next schedule
+		if *every != "" {
+			var err error
+			if next, err = parseSchedule(*every); err != nil {
+				return err
+			}
+		}
+
+		// the first sync runs right away, and its failure ends the command. Repeated syncs
+		// report each run on its own.
+		run := func(ctx context.Context) error {
+			err := syncOnce(ctx, load, *repo, *webhook)
+			if next != nil {
+				if err := currentReport.runEnded(err); err != nil {
+					warnf("%s", err)
+				}
+			}
+			return err
+		}
+		if err := run(ctx); err != nil || next == nil {
+			return err
+		}
+		return runScheduled(ctx, next, run)
+	}
+}
+
+// syncOnce loads the input and commits it to the repository, notifying the webhook, if
+// any, of the links changed.
+func syncOnce(ctx context.Context, load func(ctx context.Context) (Bookmark, error), repo, webhook string) error {
+	tree, err := load(ctx)
+	if err != nil {
+		return err
+	}
+	// browsers name their own folders differently, which is not worth a commit.
+	normalizeSpecialFolders(&tree, true)
+
+	changes, err := syncGit(ctx, repo, &tree)
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		fmt.Println("no changes")
+		return nil
+	}
+	fmt.Println(changeSummary(changes))
+	if webhook != "" && len(changes) > 0 {
+		return postWebhook(ctx, webhook, changes)
+	}
+	return nil
+}
+
+// syncGit writes the tree to the repository and commits it with a message summarizing the
+// links added, removed, and modified. It returns the changes, or nil when the export in
+// the repository was already up to date.
+func syncGit(ctx context.Context, repo string, tree *Bookmark) ([]manifestChange, error) {
+	if !isRepoRoot(ctx, repo) {
+		if err := os.MkdirAll(repo, 0755); err != nil {
+			return nil, fmt.Errorf("error creating repository: %w", err)
+		}
+		if _, err := git(ctx, repo, "init", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+
+	// the last committed export is the baseline the changes are counted against; there is
+	// none before the first commit.
+	previous := make(manifest)
+	if old, err := git(ctx, repo, "show", "HEAD:./"+syncFileName); err == nil {
+		oldTree, err := parseTreeJSON(ctx, bytes.NewReader(old), defaultRootTitle)
+		if err != nil {
+			return nil, err
+		}
+		previous = buildManifest(&oldTree)
+	}
+
+	// indented JSON puts every field on a line of its own, which keeps diffs readable.
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error converting to JSON: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(filepath.Join(repo, syncFileName), data, 0644); err != nil {
+		return nil, fmt.Errorf("error writing export: %w", err)
+	}
+	if _, err := git(ctx, repo, "add", syncFileName); err != nil {
+		return nil, err
+	}
+	if status, err := git(ctx, repo, "status", "--porcelain", "--", syncFileName); err != nil || len(status) == 0 {
+		return nil, err
+	}
+
+	changes := manifestChanges(previous, buildManifest(tree))
+	var message strings.Builder
+	message.WriteString(changeSummary(changes) + "\n")
+	if len(changes) > 0 {
+		message.WriteString("\n")
+	}
+	for _, change := range changes {
+		fmt.Fprintf(&message, "%s %s: %s <%s>\n", change.Kind, change.Folder, change.Title, change.URL)
+	}
+	// only the export is committed, whatever else may be staged.
+	if _, err := git(ctx, repo, "commit", "--quiet", "--message", message.String(), "--", syncFileName); err != nil {
+		return nil, err
+	}
+	// changes to folders alone still make a commit, with no links to report.
+	if changes == nil {
+		changes = []manifestChange{}
+	}
+	return changes, nil
+}
+
+// isRepoRoot reports whether dir is the top-level directory of a git repository, rather
+// than missing, not in a repository, or inside a repository rooted above it.
+func isRepoRoot(ctx context.Context, dir string) bool {
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false
+	}
+	want, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(want); err == nil {
+		want = resolved
+	}
+	return filepath.Clean(strings.TrimSpace(string(top))) == want
+}
+
+// git runs a git command in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
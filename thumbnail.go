@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerEnricher(enricherPlugin{
+		name:  "thumbnail",
+		usage: "capture a screenshot of each page",
+		setup: func(fs *flag.FlagSet) func(client *http.Client) (Enricher, error) {
+			dir := fs.String("thumbnail-dir", "thumbnails", "directory to store screenshots in")
+			chrome := fs.String("chrome", "", "path to the Chrome binary used for screenshots")
+			api := fs.String("screenshot-api", "", "screenshot service URL to use instead of Chrome, with {url} replaced by the page URL")
+			timeout := fs.Duration("screenshot-timeout", 30*time.Second, "time Chrome may take to capture one page")
+			return func(client *http.Client) (Enricher, error) {
+				if *timeout <= 0 {
+					return nil, fmt.Errorf("-screenshot-timeout must be positive")
+				}
+				return newThumbnailer(*dir, *chrome, *api, *timeout, client)
+			}
+		},
+	})
+}
+
+// thumbnailer captures a screenshot of each bookmarked page into a directory, either
+// with a local headless Chrome or through an external screenshot API.
+type thumbnailer struct {
+	dir     string
+	chrome  string        // path to the Chrome binary, used when api is empty.
+	api     string        // screenshot API URL with {url} standing in for the escaped page URL.
+	timeout time.Duration // bounds each Chrome run, so a hanging page cannot stall the others.
+	client  *http.Client
+}
+
+// newThumbnailer creates the output directory and locates Chrome unless an API is given.
+func newThumbnailer(dir, chrome, api string, timeout time.Duration, client *http.Client) (*thumbnailer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating thumbnail directory: %w", err)
+	}
+	if api == "" && chrome == "" {
+		for _, name := range []string{"google-chrome", "chromium", "chromium-browser", "chrome"} {
+			if path, err := exec.LookPath(name); err == nil {
+				chrome = path
+				break
+			}
+		}
+		if chrome == "" {
+			return nil, fmt.Errorf("no Chrome binary found; set -chrome or -screenshot-api")
+		}
+	}
+	return &thumbnailer{dir: dir, chrome: chrome, api: api, timeout: timeout, client: client}, nil
+}
+
+// Enrich records the path of the page's thumbnail in the bookmark.
+func (t *thumbnailer) Enrich(ctx context.Context, b *Bookmark) error {
+	path, err := t.capture(ctx, b.URL)
+	if err != nil {
+		return err
+	}
+	b.Thumbnail = path
+	return nil
+}
+
+// capture stores a thumbnail of the page and returns its path. Pages captured by an
+// earlier run are not fetched again.
+func (t *thumbnailer) capture(ctx context.Context, pageURL string) (string, error) {
+	sum := sha1.Sum([]byte(pageURL))
+	path := filepath.Join(t.dir, hex.EncodeToString(sum[:])+".png")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if t.api == "" {
+		ctx, cancel := context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, t.chrome, "--headless", "--disable-gpu", "--hide-scrollbars",
+			"--window-size=1280,800", "--screenshot="+path, pageURL)
+		// helper processes Chrome started may keep its output open after it was killed.
+		cmd.WaitDelay = time.Second
+		if output, err := cmd.CombinedOutput(); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return "", fmt.Errorf("chrome took longer than %s", t.timeout)
+			}
+			return "", fmt.Errorf("chrome failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return path, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(t.api, "{url}", url.QueryEscape(pageURL)), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("screenshot API returned %s", resp.Status)
+	}
+
+	// write to a temporary file first so a failed download leaves no partial image.
+	file, err := os.CreateTemp(t.dir, "capture-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return path, os.Rename(file.Name(), path)
+}
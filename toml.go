@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// writeTOML writes the bookmark tree as a TOML document. Entries become nested arrays of
+// tables named bookmarks, and keys use the same names as the JSON output.
+func writeTOML(w io.Writer, tree *Bookmark) error {
+	bw := bufio.NewWriter(w)
+
+	var write func(b *Bookmark, table string)
+	write = func(b *Bookmark, table string) {
+		if table != "" {
+			fmt.Fprintf(bw, "\n[[%s]]\n", table)
+		}
+		writeTOMLFields(bw, b)
+
+		// sub-tables must follow every key of the enclosing table.
+		child := "bookmarks"
+		if table != "" {
+			child = table + ".bookmarks"
+		}
+		for i := range b.Bookmarks {
+			write(&b.Bookmarks[i], child)
+		}
+	}
+	write(tree, "")
+	return bw.Flush()
+}
+
+// writeTOMLFields writes the bookmark's non-empty fields other than its entries as key/value pairs.
+func writeTOMLFields(w io.Writer, b *Bookmark) {
+	v := reflect.ValueOf(b).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		field := v.Field(i)
+		if key == "" || key == "-" || key == "bookmarks" || field.IsZero() {
+			continue
+		}
+		fmt.Fprintf(w, "%s = %s\n", key, tomlValue(field))
+	}
+}
+
+// tomlValue formats a field value as a TOML value.
+func tomlValue(v reflect.Value) string {
+	if t, ok := v.Interface().(*time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return tomlString(v.String())
+	case reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = tomlValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = tomlString(key) + " = " + tomlValue(v.MapIndex(reflect.ValueOf(key)))
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	case reflect.Struct:
+		var pairs []string
+		for i := 0; i < v.NumField(); i++ {
+			if key := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]; key != "" && key != "-" {
+				pairs = append(pairs, key+" = "+tomlValue(v.Field(i)))
+			}
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	case reflect.Ptr:
+		return tomlValue(v.Elem())
+	}
+	return tomlString(fmt.Sprint(v.Interface()))
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import "strings"
+
+// isFolder reports whether the entry is a folder rather than a link.
+func (b *Bookmark) isFolder() bool {
+	return b.URL == ""
+}
+
+// walkBookmarks calls fn for every entry below root in document order, passing the
+// titles of the folders leading to the entry (root included).
+func walkBookmarks(root *Bookmark, fn func(b *Bookmark, path []string)) {
+	var walk func(parent *Bookmark, path []string)
+	walk = func(parent *Bookmark, path []string) {
+		path = append(path, parent.Title)
+		for i := range parent.Bookmarks {
+			child := &parent.Bookmarks[i]
+			fn(child, path)
+			if child.isFolder() {
+				walk(child, path)
+			}
+		}
+	}
+	walk(root, nil)
+}
+
+// folderPath joins folder titles into a slash separated path.
+func folderPath(path []string) string {
+	return strings.Join(path, "/")
+}
+
+// addFolderStats sets the Count, DeepCount, and NewestAddAt of root and every folder
+// below it.
+func addFolderStats(root *Bookmark) {
+	count, deepCount := 0, 0
+	root.Count, root.DeepCount, root.NewestAddAt = &count, &deepCount, nil
+	for i := range root.Bookmarks {
+		b := &root.Bookmarks[i]
+		newest := b.AddAt
+		if b.isFolder() {
+			addFolderStats(b)
+			deepCount += *b.DeepCount
+			newest = b.NewestAddAt
+		} else {
+			count++
+			deepCount++
+		}
+		if newest != nil && (root.NewestAddAt == nil || newest.After(*root.NewestAddAt)) {
+			root.NewestAddAt = newest
+		}
+	}
+}
+
+// removeBookmarks deletes every entry below root for which drop returns true, along with
+// its entries. drop sees each entry at its address in the tree before removal.
+func removeBookmarks(root *Bookmark, drop func(b *Bookmark) bool) {
+	kept := root.Bookmarks[:0]
+	for i := range root.Bookmarks {
+		b := &root.Bookmarks[i]
+		if drop(b) {
+			continue
+		}
+		if b.isFolder() {
+			removeBookmarks(b, drop)
+		}
+		kept = append(kept, *b)
+	}
+	root.Bookmarks = kept
+}
+
+// relocate moves links to the folders targets maps them to, given as folder titles below
+// root, creating the folders as needed. Moved links keep their document order.
+func relocate(root *Bookmark, targets map[*Bookmark][]string) {
+	type move struct {
+		link   Bookmark
+		folder []string
+	}
+	var moves []move
+	removeBookmarks(root, func(b *Bookmark) bool {
+		folder, ok := targets[b]
+		if ok {
+			moves = append(moves, move{*b, folder})
+		}
+		return ok
+	})
+	for _, m := range moves {
+		folder := ensureFolder(root, m.folder)
+		folder.Bookmarks = append(folder.Bookmarks, m.link)
+	}
+}
+
+// ensureFolder returns the folder reached from root by following titles, creating the
+// folders missing along the way. The pointer is valid until the entries of one of its
+// ancestors change.
+func ensureFolder(root *Bookmark, titles []string) *Bookmark {
+	folder := root
+next:
+	for _, title := range titles {
+		for i := range folder.Bookmarks {
+			if child := &folder.Bookmarks[i]; child.isFolder() && child.Title == title {
+				folder = child
+				continue next
+			}
+		}
+		folder.Bookmarks = append(folder.Bookmarks, Bookmark{Title: title})
+		folder = &folder.Bookmarks[len(folder.Bookmarks)-1]
+	}
+	return folder
+}
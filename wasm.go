@@ -0,0 +1,38 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"syscall/js"
+)
+
+// main exposes the parser to JavaScript as parseBookmarks(html), which returns the
+// bookmark tree as a JSON string, or an Error describing why parsing failed. Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o parse-bookmarks.wasm
+//
+// and load it with the wasm_exec.js shipped in $(go env GOROOT)/lib/wasm.
+func main() {
+	js.Global().Set("parseBookmarks", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		// a panic here would stop the Go program, so failures are returned instead.
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return js.Global().Get("TypeError").New("parseBookmarks expects the exported HTML as a string")
+		}
+
+		tree, err := parseHTML(context.Background(), strings.NewReader(args[0].String()), defaultRootTitle)
+		if err != nil {
+			return js.Global().Get("Error").New(err.Error())
+		}
+		var output bytes.Buffer
+		if err := writeJSON(&output, &tree); err != nil {
+			return js.Global().Get("Error").New(err.Error())
+		}
+		return strings.TrimSpace(output.String())
+	}))
+
+	// keep the program alive so the exported function stays callable.
+	select {}
+}
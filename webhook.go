@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds the delivery of a change notification.
+const webhookTimeout = 30 * time.Second
+
+// webhookPayload is the JSON body posted to a webhook. Text repeats the summary under the
+// name Slack's incoming webhooks display.
+type webhookPayload struct {
+	Text     string          `json:"text"`
+	Added    []manifestEntry `json:"added"`
+	Removed  []manifestEntry `json:"removed"`
+	Modified []manifestEntry `json:"modified"`
+}
+
+// postWebhook posts the changes to url as a webhookPayload.
+func postWebhook(ctx context.Context, url string, changes []manifestChange) error {
+	payload := webhookPayload{
+		Text:     "bookmarks changed: " + changeSummary(changes),
+		Added:    []manifestEntry{},
+		Removed:  []manifestEntry{},
+		Modified: []manifestEntry{},
+	}
+	for _, change := range changes {
+		switch change.Kind {
+		case "added":
+			payload.Added = append(payload.Added, change.manifestEntry)
+		case "removed":
+			payload.Removed = append(payload.Removed, change.manifestEntry)
+		case "modified":
+			payload.Modified = append(payload.Modified, change.manifestEntry)
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error posting webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error posting webhook: %s", resp.Status)
+	}
+	return nil
+}